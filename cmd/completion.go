@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"os"
+
+	"system-eye/internal/collector"
+
+	"github.com/spf13/cobra"
+)
+
+// completionCmd defines the "completion" command.
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish|powershell]",
+	Short: "Generate a shell completion script",
+	Long: `Generates a completion script for the given shell, covering every flag on
+every subcommand (--mode, --config, --resolution, and so on). Flags backed by
+a fixed set of values, such as --mode, complete to that set; --plugin
+completes to whatever MetricPlugin names are registered in the running
+binary at completion time.
+
+To load completions:
+
+Bash:
+  source <(system-eye completion bash)
+
+Zsh:
+  system-eye completion zsh > "${fpath[1]}/_system-eye"
+
+Fish:
+  system-eye completion fish | source
+
+PowerShell:
+  system-eye completion powershell | Out-String | Invoke-Expression
+`,
+	ValidArgs: []string{"bash", "zsh", "fish", "powershell"},
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	Run: func(cmd *cobra.Command, args []string) {
+		switch args[0] {
+		case "bash":
+			rootCmd.GenBashCompletion(os.Stdout)
+		case "zsh":
+			rootCmd.GenZshCompletion(os.Stdout)
+		case "fish":
+			rootCmd.GenFishCompletion(os.Stdout, true)
+		case "powershell":
+			rootCmd.GenPowerShellCompletionWithDesc(os.Stdout)
+		}
+	},
+}
+
+// pluginNameCompletions lists the names of every currently registered
+// MetricPlugin, for shell completion of flags like --plugin. It runs at
+// completion time (not at script-generation time), so it reflects whatever
+// plugins the embedding binary has registered via RegisterPlugin.
+func pluginNameCompletions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var names []string
+	for _, p := range collector.Plugins() {
+		names = append(names, p.Name())
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+}
@@ -4,15 +4,19 @@ import (
 	"context"
 	"encoding/csv"
 	"fmt"
-	"io"
 	"log"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"strconv"
-	"sync"
+	"strings"
 	"time"
 
+	"system-eye/internal/alerts"
+	"system-eye/internal/cgroup"
+	"system-eye/internal/collector"
+	"system-eye/internal/downsample"
+	"system-eye/internal/store"
 	"system-eye/internal/system"
 
 	ui "github.com/gizak/termui/v3"
@@ -25,6 +29,11 @@ import (
 type Config struct {
 	RefreshInterval time.Duration `mapstructure:"refresh_interval"` // e.g. "2s"
 	CPUThreshold    float64       `mapstructure:"cpu_threshold"`    // e.g. 90.0 (%)
+
+	// Rules declares the alerting rules evaluated against each collected
+	// Metric, and Notifiers configures where their AlertEvents are sent.
+	Rules     []alerts.RuleConfig              `mapstructure:"rules"`
+	Notifiers map[string]alerts.NotifierConfig `mapstructure:"notifiers"`
 }
 
 // Global configuration instance.
@@ -34,27 +43,15 @@ var config = Config{
 }
 
 // Metric represents system metrics along with a timestamp.
-type Metric struct {
-	Timestamp time.Time
-	CPUUsage  float64
-	MemUsage  float64
-	DiskUsage float64
-}
+type Metric = collector.Metric
 
 // MetricPlugin defines an interface for extensible metric plugins.
-type MetricPlugin interface {
-	// Name returns the plugin's name.
-	Name() string
-	// Fetch returns the plugin's metric value.
-	Fetch() (float64, error)
-}
-
-// pluginRegistry holds all registered plugins.
-var pluginRegistry []MetricPlugin
+type MetricPlugin = collector.MetricPlugin
 
-// RegisterPlugin adds a new plugin to the registry.
+// RegisterPlugin adds a new plugin to the registry shared by the TUI
+// dashboard and the HTTP exporter.
 func RegisterPlugin(p MetricPlugin) {
-	pluginRegistry = append(pluginRegistry, p)
+	collector.RegisterPlugin(p)
 }
 
 var (
@@ -64,6 +61,11 @@ var (
 	toTime          string
 	refreshInterval time.Duration // Overrides config.RefreshInterval if set via flag.
 	configFile      string        // Path to configuration file.
+	cgroupPath      string        // If set, scope collection to this cgroup instead of the whole host.
+	cgroupPID       int           // If set, scope collection to the cgroup(s) owning this PID.
+	resolution      string        // History mode: "auto" or a target point count, e.g. "200".
+	rollup          string        // History mode: "lttb" (default) or "minmax".
+	pluginFilter    string        // Live mode: if set, log this MetricPlugin's value on every sample.
 )
 
 // dashboardCmd defines the "dashboard" command.
@@ -83,13 +85,13 @@ Flags:
       --to         End time for historical data (RFC3339 format).
   -i, --interval   Refresh interval for live mode (default: 2s).
       --config     Path to configuration file (YAML, JSON, etc).
+      --cgroup     Scope collection to this cgroup instead of the whole host.
+      --pid        Scope collection to the cgroup(s) owning this PID instead of the whole host.
+      --resolution History mode: "auto" (fit the terminal width) or a target point count (default: "auto").
+      --rollup     History mode: "lttb" (preserves peaks/troughs, default) or "minmax" (alert-style min/max pairs per bucket).
+      --plugin     Live mode: log this registered MetricPlugin's value on every sample.
 
-Interactive Controls:
-  p               Toggle pause/resume live updates.
-  z               Zoom in (decrease refresh interval for more detail).
-  x               Zoom out (increase refresh interval for a broader view).
-  e               Export current live metrics to CSV.
-  q, Ctrl+C       Quit the dashboard.
+Press "?" inside the live dashboard for an interactive list of keybindings.
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Load configuration file if provided.
@@ -124,6 +126,21 @@ func init() {
 	dashboardCmd.Flags().StringVar(&toTime, "to", "", "End time for historical data (RFC3339 format)")
 	dashboardCmd.Flags().DurationVarP(&refreshInterval, "interval", "i", 0, "Refresh interval for live mode (overrides config file)")
 	dashboardCmd.Flags().StringVar(&configFile, "config", "", "Path to configuration file (YAML, JSON, etc)")
+	dashboardCmd.Flags().StringVar(&cgroupPath, "cgroup", "", "Scope collection to this cgroup (path relative to the cgroup mount) instead of the whole host")
+	dashboardCmd.Flags().IntVar(&cgroupPID, "pid", 0, "Scope collection to the cgroup(s) owning this PID instead of the whole host")
+	dashboardCmd.Flags().StringVar(&resolution, "resolution", "auto", `History mode: "auto" (fit the terminal width) or a target point count`)
+	dashboardCmd.Flags().StringVar(&rollup, "rollup", "lttb", `History mode: "lttb" (preserves peaks/troughs) or "minmax" (alert-style min/max pairs per bucket)`)
+	dashboardCmd.Flags().StringVar(&pluginFilter, "plugin", "", "Live mode: log this registered MetricPlugin's value on every sample")
+
+	dashboardCmd.MarkFlagFilename("config")
+	dashboardCmd.MarkFlagFilename("csv")
+	dashboardCmd.RegisterFlagCompletionFunc("mode", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"live", "history"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	dashboardCmd.RegisterFlagCompletionFunc("rollup", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"lttb", "minmax"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	dashboardCmd.RegisterFlagCompletionFunc("plugin", pluginNameCompletions)
 }
 
 // loadConfig loads configuration values from the specified file using Viper.
@@ -135,6 +152,69 @@ func loadConfig(path string) error {
 	return viper.Unmarshal(&config)
 }
 
+// newCollector builds the shared Collector, scoping it to a single cgroup
+// when --cgroup or --pid was given. It is used by both the dashboard and the
+// HTTP exporter so they never disagree about what scope is being reported.
+func newCollector(interval time.Duration) (*collector.Collector, error) {
+	switch {
+	case cgroupPath != "":
+		reader, err := cgroup.NewReader(cgroupPath)
+		if err != nil {
+			return nil, fmt.Errorf("resolving cgroup %q: %w", cgroupPath, err)
+		}
+		return collector.NewScoped(interval, reader, cgroupPath), nil
+	case cgroupPID != 0:
+		reader, err := cgroup.ReaderForPID(cgroupPID)
+		if err != nil {
+			return nil, fmt.Errorf("resolving cgroup for pid %d: %w", cgroupPID, err)
+		}
+		return collector.NewScoped(interval, reader, fmt.Sprintf("pid %d", cgroupPID)), nil
+	default:
+		return collector.New(interval), nil
+	}
+}
+
+// Keybinding is the single source of truth for a live-dashboard keypress: its
+// Action runs when Key is pressed, and it also supplies the line shown for
+// that key in the "?" help overlay. Action returns true to quit the
+// dashboard's event loop.
+type Keybinding struct {
+	Key         string
+	Description string
+	Action      func() (quit bool)
+}
+
+// helpOverlay renders bindings as a centered modal Paragraph listing every
+// keybinding, for the "?" help overlay.
+func helpOverlay(bindings []Keybinding) *widgets.Paragraph {
+	var b strings.Builder
+	longest := 0
+	for _, kb := range bindings {
+		line := fmt.Sprintf("%-10s %s", kb.Key, kb.Description)
+		if len(line) > longest {
+			longest = len(line)
+		}
+		b.WriteString(line + "\n")
+	}
+
+	p := widgets.NewParagraph()
+	p.Title = "Keybindings (press ? to close)"
+	p.Text = strings.TrimRight(b.String(), "\n")
+	p.BorderStyle.Fg = ui.ColorYellow
+
+	// Size the modal to its longest line plus the border, so no line wraps
+	// and pushes a later binding past the modal's bottom edge.
+	w, h := ui.TerminalDimensions()
+	modalWidth, modalHeight := 40, len(bindings)+2
+	if longest+2 > modalWidth {
+		modalWidth = longest + 2
+	}
+	x1 := (w - modalWidth) / 2
+	y1 := (h - modalHeight) / 2
+	p.SetRect(x1, y1, x1+modalWidth, y1+modalHeight)
+	return p
+}
+
 // ensureMinData ensures that the slice has at least 2 data points to avoid termui errors.
 func ensureMinData(data []float64) []float64 {
 	if len(data) < 2 {
@@ -158,15 +238,21 @@ func createPlot(title string, data []float64, x1, y1, x2, y2 int, lineColor ui.C
 }
 
 // updateWidgetRects updates the rectangles for all widgets based on current terminal dimensions.
-// It divides the terminal into a chart area (top) and a log area (bottom, fixed height).
-func updateWidgetRects(cpuChart, memChart, diskChart *widgets.Plot, logWidget *widgets.List) {
+// It divides the terminal into a chart area (top), an I/O and per-core area
+// (middle), and a log area (bottom, fixed height).
+func updateWidgetRects(cpuChart, memChart, diskChart *widgets.Plot, ioSparklines *widgets.SparklineGroup, coreGauges *widgets.List, logWidget *widgets.List) {
 	w, h := ui.TerminalDimensions()
 	logHeight := 5 // Reserve 5 lines for logs.
+	ioHeight := 8  // Reserve 8 lines for the net/disk I/O sparklines and core gauges.
+	chartBottom := h - logHeight - ioHeight
 	// CPU and Memory charts occupy the top half.
-	cpuChart.SetRect(0, 0, w/2, h/2)
-	memChart.SetRect(w/2, 0, w, h/2)
-	// Disk chart occupies the area from half height to above the log area.
-	diskChart.SetRect(0, h/2, w, h-logHeight)
+	cpuChart.SetRect(0, 0, w/2, chartBottom/2)
+	memChart.SetRect(w/2, 0, w, chartBottom/2)
+	// Disk chart occupies the area from half height to above the I/O row.
+	diskChart.SetRect(0, chartBottom/2, w, chartBottom)
+	// Net/disk I/O sparklines and per-core gauges share the I/O row.
+	ioSparklines.SetRect(0, chartBottom, w/2, chartBottom+ioHeight)
+	coreGauges.SetRect(w/2, chartBottom, w, chartBottom+ioHeight)
 	// Log widget occupies the bottom logHeight lines.
 	logWidget.SetRect(0, h-logHeight, w, h)
 }
@@ -184,7 +270,9 @@ func runLiveDashboard() {
 	defer cancel()
 
 	var history []Metric
+	var alertHistory []alerts.AlertEvent
 	var cpuData, memData, diskData []float64
+	var netRateData, diskRateData []float64
 	var logMessages []string // Holds recent log messages.
 
 	// Create log widget.
@@ -203,20 +291,127 @@ func runLiveDashboard() {
 		logWidget.Rows = logMessages
 	}
 
+	// chartSuffix labels every chart with the cgroup scope, if any, so it's
+	// obvious at a glance that the numbers describe a container or service
+	// rather than the whole host.
+	chartSuffix := ""
+	if cgroupPath != "" {
+		chartSuffix = fmt.Sprintf(" [%s]", cgroupPath)
+	} else if cgroupPID != 0 {
+		chartSuffix = fmt.Sprintf(" [pid %d]", cgroupPID)
+	}
+
 	// Create plot widgets for charts.
-	cpuChart := createPlot("CPU Usage (%)", cpuData, 0, 0, 50, 12, ui.ColorGreen)
-	memChart := createPlot("Memory Usage (%)", memData, 50, 0, 100, 12, ui.ColorYellow)
-	diskChart := createPlot("Disk Usage (%)", diskData, 0, 12, 100, 24, ui.ColorCyan)
+	cpuChart := createPlot("CPU Usage (%)"+chartSuffix, cpuData, 0, 0, 50, 12, ui.ColorGreen)
+	memChart := createPlot("Memory Usage (%)"+chartSuffix, memData, 50, 0, 100, 12, ui.ColorYellow)
+	diskChart := createPlot("Disk Usage (%)"+chartSuffix, diskData, 0, 12, 100, 24, ui.ColorCyan)
+
+	// netSparkline and diskSparkline show the combined throughput across all
+	// interfaces/devices since the previous sample; coreGauges lists each
+	// logical CPU core's usage as a textual bar.
+	netSparkline := widgets.NewSparkline()
+	netSparkline.Title = "Net I/O (B/s)"
+	netSparkline.LineColor = ui.ColorMagenta
+	diskSparkline := widgets.NewSparkline()
+	diskSparkline.Title = "Disk I/O (B/s)"
+	diskSparkline.LineColor = ui.ColorBlue
+	ioSparklines := widgets.NewSparklineGroup(netSparkline, diskSparkline)
+	ioSparklines.Title = "Throughput"
+
+	coreGauges := widgets.NewList()
+	coreGauges.Title = "Per-Core CPU"
 
 	// Set initial widget sizes based on terminal dimensions.
-	updateWidgetRects(cpuChart, memChart, diskChart, logWidget)
+	updateWidgetRects(cpuChart, memChart, diskChart, ioSparklines, coreGauges, logWidget)
 
-	// Use refresh interval from config.
-	ticker := time.NewTicker(config.RefreshInterval)
-	defer ticker.Stop()
+	// coll is this dashboard's Collector, the same type (built by the same
+	// newCollector) that the HTTP exporter runs; it owns the sampling loop
+	// and ticker that used to live directly in this function.
+	coll, err := newCollector(config.RefreshInterval)
+	if err != nil {
+		log.Fatalf("failed to start collector: %v", err)
+	}
+	collCtx, collCancel := context.WithCancel(ctx)
+	defer collCancel()
+	go coll.Run(collCtx, func(err error) {
+		addLog(fmt.Sprintf("Error collecting metrics: %v", err))
+	})
+
+	samples, unsubscribe := coll.Subscribe()
+	defer unsubscribe()
+
+	// Build the alerting engine from the loaded configuration, if any rules
+	// were declared.
+	notifiers, err := alerts.BuildNotifiers(config.Notifiers)
+	if err != nil {
+		addLog(fmt.Sprintf("Failed to configure alert notifiers: %v", err))
+	}
+	evaluator := alerts.NewRuleEvaluator(config.Rules, notifiers)
+	evaluator.OnNotifyError = func(err error) {
+		addLog(fmt.Sprintf("Alert notifier error: %v", err))
+	}
 
 	uiEvents := ui.PollEvents()
 	paused := false
+	showHelp := false
+
+	// keybindings is the single source of truth for every key the live
+	// dashboard responds to: it drives both the dispatch below and the "?"
+	// help overlay's contents, so a new key only needs to be added here.
+	keybindings := []Keybinding{
+		{Key: "p", Description: "Toggle pause/resume live updates", Action: func() bool {
+			paused = !paused
+			if paused {
+				addLog("Live updates paused.")
+			} else {
+				addLog("Live updates resumed.")
+			}
+			return false
+		}},
+		{Key: "z", Description: "Zoom in (shorter refresh interval)", Action: func() bool {
+			config.RefreshInterval = config.RefreshInterval / 2
+			addLog(fmt.Sprintf("Zoom in: new refresh interval: %v", config.RefreshInterval))
+			coll.SetInterval(config.RefreshInterval)
+			return false
+		}},
+		{Key: "x", Description: "Zoom out (longer refresh interval)", Action: func() bool {
+			config.RefreshInterval = config.RefreshInterval * 2
+			addLog(fmt.Sprintf("Zoom out: new refresh interval: %v", config.RefreshInterval))
+			coll.SetInterval(config.RefreshInterval)
+			return false
+		}},
+		{Key: "e", Description: "Export current live metrics to CSV", Action: func() bool {
+			if csvPath != "" {
+				if err := exportHistory(csvPath, history); err != nil {
+					addLog(fmt.Sprintf("Failed to export CSV: %v", err))
+				} else {
+					addLog(fmt.Sprintf("Exported history to %s", csvPath))
+				}
+				if err := exportAlertsToCSV(alertsCSVPath(csvPath), alertHistory); err != nil {
+					addLog(fmt.Sprintf("Failed to export alerts CSV: %v", err))
+				}
+			}
+			return false
+		}},
+		{Key: "?", Description: "Toggle this help overlay", Action: func() bool {
+			showHelp = !showHelp
+			return false
+		}},
+		{Key: "q", Description: "Quit the dashboard", Action: func() bool {
+			addLog("Quit event received.")
+			return true
+		}},
+	}
+	help := helpOverlay(keybindings)
+
+	// render draws every widget, plus the help overlay on top when toggled on.
+	render := func() {
+		if showHelp {
+			ui.Render(cpuChart, memChart, diskChart, ioSparklines, coreGauges, logWidget, help)
+		} else {
+			ui.Render(cpuChart, memChart, diskChart, ioSparklines, coreGauges, logWidget)
+		}
+	}
 
 loop:
 	for {
@@ -225,122 +420,89 @@ loop:
 			addLog("Shutdown signal received. Exiting live dashboard.")
 			break loop
 		case e := <-uiEvents:
-			switch e.ID {
-			case "q", "<C-c>":
+			switch {
+			case e.ID == "<C-c>":
 				addLog("Quit event received.")
 				break loop
-			case "p":
-				paused = !paused
-				if paused {
-					addLog("Live updates paused.")
-				} else {
-					addLog("Live updates resumed.")
-				}
-			case "z":
-				// Zoom in: decrease refresh interval to show more detail.
-				config.RefreshInterval = config.RefreshInterval / 2
-				addLog(fmt.Sprintf("Zoom in: new refresh interval: %v", config.RefreshInterval))
-				ticker.Reset(config.RefreshInterval)
-			case "x":
-				// Zoom out: increase refresh interval for a broader view.
-				config.RefreshInterval = config.RefreshInterval * 2
-				addLog(fmt.Sprintf("Zoom out: new refresh interval: %v", config.RefreshInterval))
-				ticker.Reset(config.RefreshInterval)
-			case "e":
-				// Export CSV immediately when 'e' is pressed.
-				if csvPath != "" {
-					if err := exportHistoryToCSV(csvPath, history); err != nil {
-						addLog(fmt.Sprintf("Failed to export CSV: %v", err))
-					} else {
-						addLog(fmt.Sprintf("Exported history to %s", csvPath))
+			case e.ID == "<Resize>":
+				updateWidgetRects(cpuChart, memChart, diskChart, ioSparklines, coreGauges, logWidget)
+			default:
+				for _, kb := range keybindings {
+					if e.ID == kb.Key {
+						if kb.Action() {
+							break loop
+						}
+						break
 					}
 				}
-			case "<Resize>":
-				updateWidgetRects(cpuChart, memChart, diskChart, logWidget)
 			}
-		case <-ticker.C:
+			render()
+		case m := <-samples:
 			if paused {
 				continue
 			}
-			// Retrieve metrics concurrently.
-			var wg sync.WaitGroup
-			var cpuUsage, memUsage, diskUsage float64
-			var errCPU, errMem, errDisk error
-
-			wg.Add(3)
-			go func() {
-				defer wg.Done()
-				cpuUsage, errCPU = system.GetCPUUsage()
-			}()
-			go func() {
-				defer wg.Done()
-				memUsage, errMem = system.GetMemoryUsage()
-			}()
-			go func() {
-				defer wg.Done()
-				diskUsage, errDisk = system.GetDiskUsage("/")
-			}()
-			wg.Wait()
-
-			// Handle any errors from metric collection.
-			if errCPU != nil {
-				addLog(fmt.Sprintf("Error retrieving CPU usage: %v", errCPU))
-				continue
-			}
-			if errMem != nil {
-				addLog(fmt.Sprintf("Error retrieving Memory usage: %v", errMem))
-				continue
-			}
-			if errDisk != nil {
-				addLog(fmt.Sprintf("Error retrieving Disk usage: %v", errDisk))
-				continue
-			}
 
 			// Log notification if CPU usage exceeds threshold.
-			if cpuUsage > config.CPUThreshold {
-				addLog(fmt.Sprintf("High CPU usage detected: %.2f%%", cpuUsage))
+			if m.CPUUsage > config.CPUThreshold {
+				addLog(fmt.Sprintf("High CPU usage detected: %.2f%%", m.CPUUsage))
 				// (Optional: integrate with a notification system here)
 			}
 
-			now := time.Now()
-			history = append(history, Metric{
-				Timestamp: now,
-				CPUUsage:  cpuUsage,
-				MemUsage:  memUsage,
-				DiskUsage: diskUsage,
-			})
+			// Evaluate alerting rules and surface any crossings in the log.
+			for _, event := range evaluator.Evaluate(m) {
+				alertHistory = append(alertHistory, event)
+				addLog(event.String())
+			}
+
+			if pluginFilter != "" {
+				if value, ok := m.Plugins[pluginFilter]; ok {
+					addLog(fmt.Sprintf("%s: %.2f", pluginFilter, value))
+				}
+			}
+
+			history = append(history, m)
 
 			// Maintain only the latest 30 data points.
-			cpuData = appendValue(cpuData, cpuUsage, 30)
-			memData = appendValue(memData, memUsage, 30)
-			diskData = appendValue(diskData, diskUsage, 30)
+			cpuData = appendValue(cpuData, m.CPUUsage, 30)
+			memData = appendValue(memData, m.MemUsage, 30)
+			diskData = appendValue(diskData, m.DiskUsage, 30)
+			netRateData = appendValue(netRateData, totalNetBytesRate(m.NetIO), 30)
+			diskRateData = appendValue(diskRateData, totalDiskBytesRate(m.DiskIO), 30)
 
 			// Update chart data.
 			cpuChart.Data = [][]float64{ensureMinData(cpuData)}
 			memChart.Data = [][]float64{ensureMinData(memData)}
 			diskChart.Data = [][]float64{ensureMinData(diskData)}
+			netSparkline.Data = ensureMinData(netRateData)
+			diskSparkline.Data = ensureMinData(diskRateData)
+			coreGauges.Rows = coreGaugeRows(m.PerCoreUsage)
 
-			// Render all widgets (charts and logs).
-			ui.Render(cpuChart, memChart, diskChart, logWidget)
+			// Render all widgets (charts, I/O sparklines, core gauges, logs,
+			// and the help overlay if it's toggled on).
+			render()
 		}
 	}
 
 	// On exit, export CSV if the path is provided.
 	if csvPath != "" {
-		if err := exportHistoryToCSV(csvPath, history); err != nil {
+		if err := exportHistory(csvPath, history); err != nil {
 			addLog(fmt.Sprintf("Failed to export CSV on shutdown: %v", err))
 		} else {
 			addLog(fmt.Sprintf("Exported history to %s", csvPath))
 		}
+		if err := exportAlertsToCSV(alertsCSVPath(csvPath), alertHistory); err != nil {
+			addLog(fmt.Sprintf("Failed to export alerts CSV on shutdown: %v", err))
+		}
 		// Render final state to show the export message.
 		ui.Render(logWidget)
 	}
 }
 
-// runHistoryDashboard reads a CSV file and displays charts for the specified time range.
+// runHistoryDashboard reads history from a HistoryStore and displays charts
+// for the specified time range.
 func runHistoryDashboard() {
 	if csvPath == "" {
-		fmt.Println("CSV file path must be provided in history mode.")
+		fmt.Println("History file path must be provided in history mode.")
 		return
 	}
 	var start, end time.Time
@@ -360,19 +522,28 @@ func runHistoryDashboard() {
 		}
 	}
 
-	history, err := readHistoryFromCSV(csvPath)
+	// store.Open creates the file if it doesn't exist, which would make a
+	// mistyped --csv silently "succeed" with an empty, newly-created history
+	// file instead of failing. Stat it first so a bad path fails loudly.
+	if _, err := os.Stat(csvPath); err != nil {
+		fmt.Println("Error opening history store:", err)
+		return
+	}
+
+	// Query the store directly for the requested range, rather than reading
+	// every record and filtering in memory: the binary and SQLite backends
+	// seek straight to the range via their index.
+	hist, err := store.Open(store.BackendForPath(csvPath), csvPath)
 	if err != nil {
-		fmt.Println("Error reading CSV:", err)
+		fmt.Println("Error opening history store:", err)
 		return
 	}
+	defer hist.Close()
 
-	// Filter data based on the specified time range.
-	var filtered []Metric
-	for _, m := range history {
-		if (!start.IsZero() && m.Timestamp.Before(start)) || (!end.IsZero() && m.Timestamp.After(end)) {
-			continue
-		}
-		filtered = append(filtered, m)
+	filtered, err := hist.Query(start, end)
+	if err != nil {
+		fmt.Println("Error querying history store:", err)
+		return
 	}
 	if len(filtered) == 0 {
 		fmt.Println("No data available for the specified time range.")
@@ -380,10 +551,10 @@ func runHistoryDashboard() {
 	}
 
 	var cpuData, memData, diskData []float64
-	for _, m := range filtered {
-		cpuData = append(cpuData, m.CPUUsage)
-		memData = append(memData, m.MemUsage)
-		diskData = append(diskData, m.DiskUsage)
+	for _, r := range filtered {
+		cpuData = append(cpuData, r.CPUUsage)
+		memData = append(memData, r.MemUsage)
+		diskData = append(diskData, r.DiskUsage)
 	}
 
 	if err := ui.Init(); err != nil {
@@ -391,6 +562,22 @@ func runHistoryDashboard() {
 	}
 	defer ui.Close()
 
+	// Downsample to roughly one point per terminal column so a time range
+	// much wider than the chart doesn't render as an unreadable smear.
+	target, err := resolutionTarget(resolution)
+	if err != nil {
+		fmt.Println("Invalid --resolution:", err)
+		return
+	}
+	rollupFn, err := rollupFunc(rollup)
+	if err != nil {
+		fmt.Println("Invalid --rollup:", err)
+		return
+	}
+	cpuData = rollupFn(cpuData, target)
+	memData = rollupFn(memData, target)
+	diskData = rollupFn(diskData, target)
+
 	cpuChart := createPlot(fmt.Sprintf("CPU Usage (%%) [%s - %s]", fromTime, toTime), cpuData, 0, 0, 50, 12, ui.ColorGreen)
 	memChart := createPlot(fmt.Sprintf("Memory Usage (%%) [%s - %s]", fromTime, toTime), memData, 50, 0, 100, 12, ui.ColorYellow)
 	diskChart := createPlot(fmt.Sprintf("Disk Usage (%%) [%s - %s]", fromTime, toTime), diskData, 0, 12, 100, 24, ui.ColorCyan)
@@ -405,6 +592,36 @@ func runHistoryDashboard() {
 	}
 }
 
+// resolutionTarget resolves the --resolution flag to a target point count:
+// "auto" fits the current terminal width, and anything else is parsed as an
+// explicit point count.
+func resolutionTarget(resolution string) (int, error) {
+	if resolution == "" || resolution == "auto" {
+		w, _ := ui.TerminalDimensions()
+		return w, nil
+	}
+	n, err := strconv.Atoi(resolution)
+	if err != nil {
+		return 0, fmt.Errorf("must be \"auto\" or an integer: %w", err)
+	}
+	return n, nil
+}
+
+// rollupFunc resolves the --rollup flag to a downsampling algorithm: "lttb"
+// preserves the shape of the series (peaks and troughs), while "minmax"
+// guarantees every spike is visible at the cost of distorting that shape,
+// which suits alert-style "did this ever cross X" views.
+func rollupFunc(rollup string) (func(data []float64, threshold int) []float64, error) {
+	switch rollup {
+	case "", "lttb":
+		return downsample.LTTB, nil
+	case "minmax":
+		return downsample.MinMaxRollup, nil
+	default:
+		return nil, fmt.Errorf("must be \"lttb\" or \"minmax\", got %q", rollup)
+	}
+}
+
 // appendValue adds a new value to the slice, maintaining a maximum length of maxLen.
 func appendValue(data []float64, value float64, maxLen int) []float64 {
 	if len(data) >= maxLen {
@@ -413,25 +630,99 @@ func appendValue(data []float64, value float64, maxLen int) []float64 {
 	return append(data, value)
 }
 
-// exportHistoryToCSV writes the historical metrics to a CSV file.
+// totalNetBytesRate sums sent+received throughput across every network
+// interface, for a single combined sparkline.
+func totalNetBytesRate(stats []system.NetIOStat) float64 {
+	var total float64
+	for _, s := range stats {
+		total += s.BytesSentRate + s.BytesRecvRate
+	}
+	return total
+}
+
+// totalDiskBytesRate sums read+write throughput across every block device,
+// for a single combined sparkline.
+func totalDiskBytesRate(stats []system.DiskIOStat) float64 {
+	var total float64
+	for _, s := range stats {
+		total += s.ReadBytesRate + s.WriteBytesRate
+	}
+	return total
+}
+
+// coreGaugeRows renders one textual gauge row per logical CPU core, e.g.
+// "Core 0  [████████············]  42.0%".
+func coreGaugeRows(perCore []float64) []string {
+	const barWidth = 20
+	rows := make([]string, len(perCore))
+	for i, pct := range perCore {
+		filled := int(pct / 100 * barWidth)
+		if filled > barWidth {
+			filled = barWidth
+		}
+		bar := strings.Repeat("█", filled) + strings.Repeat("·", barWidth-filled)
+		rows[i] = fmt.Sprintf("Core %-2d [%s] %5.1f%%", i, bar, pct)
+	}
+	return rows
+}
+
+// exportHistory writes the full Metric history to path, using the
+// HistoryStore backend inferred from its extension (see
+// store.BackendForPath). It writes to a temporary file using the same
+// backend and then atomically renames it into place, so a reader never
+// observes a partially-written export.
+func exportHistory(path string, history []Metric) error {
+	backend := store.BackendForPath(path)
+
+	dir, file := filepath.Split(path)
+	tmpPath := filepath.Join(dir, file+".tmp")
+	os.Remove(tmpPath) // best-effort cleanup from a previous failed export
+
+	s, err := store.Open(backend, tmpPath)
+	if err != nil {
+		return err
+	}
+	for _, m := range history {
+		if err := s.Append(store.RecordFromMetric(m)); err != nil {
+			s.Close()
+			return err
+		}
+	}
+	if err := s.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// alertsCSVPath derives the alert log path from the metrics CSV path, e.g.
+// "history.csv" becomes "history.alerts.csv".
+func alertsCSVPath(metricsCSVPath string) string {
+	ext := filepath.Ext(metricsCSVPath)
+	return strings.TrimSuffix(metricsCSVPath, ext) + ".alerts" + ext
+}
+
+// exportAlertsToCSV writes the recorded alert crossings to a CSV file.
 // Data is first written to a temporary file and then atomically renamed to reduce data corruption risk.
-func exportHistoryToCSV(filename string, history []Metric) error {
+func exportAlertsToCSV(filename string, events []alerts.AlertEvent) error {
 	dir, file := filepath.Split(filename)
 	tmpFile, err := os.CreateTemp(dir, file)
 	if err != nil {
 		return err
 	}
 	writer := csv.NewWriter(tmpFile)
-	if err := writer.Write([]string{"timestamp", "cpu_usage", "mem_usage", "disk_usage"}); err != nil {
+	if err := writer.Write([]string{"timestamp", "rule", "metric", "value", "threshold", "severity", "state"}); err != nil {
 		tmpFile.Close()
 		return err
 	}
-	for _, m := range history {
+	for _, e := range events {
 		record := []string{
-			m.Timestamp.Format(time.RFC3339),
-			fmt.Sprintf("%.2f", m.CPUUsage),
-			fmt.Sprintf("%.2f", m.MemUsage),
-			fmt.Sprintf("%.2f", m.DiskUsage),
+			e.Timestamp.Format(time.RFC3339),
+			e.Rule,
+			e.Metric,
+			fmt.Sprintf("%.2f", e.Value),
+			fmt.Sprintf("%.2f", e.Threshold),
+			e.Severity,
+			string(e.State),
 		}
 		if err := writer.Write(record); err != nil {
 			tmpFile.Close()
@@ -447,54 +738,3 @@ func exportHistoryToCSV(filename string, history []Metric) error {
 	// Atomically move the temporary file.
 	return os.Rename(tmpFile.Name(), filename)
 }
-
-// readHistoryFromCSV reads metrics history from a CSV file.
-func readHistoryFromCSV(filename string) ([]Metric, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	var history []Metric
-	reader := csv.NewReader(file)
-	// Skip the header.
-	if _, err := reader.Read(); err != nil {
-		return nil, err
-	}
-	for {
-		record, err := reader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return nil, err
-		}
-		if len(record) < 4 {
-			continue
-		}
-		t, err := time.Parse(time.RFC3339, record[0])
-		if err != nil {
-			continue
-		}
-		cpuVal, err := strconv.ParseFloat(record[1], 64)
-		if err != nil {
-			continue
-		}
-		memVal, err := strconv.ParseFloat(record[2], 64)
-		if err != nil {
-			continue
-		}
-		diskVal, err := strconv.ParseFloat(record[3], 64)
-		if err != nil {
-			continue
-		}
-		history = append(history, Metric{
-			Timestamp: t,
-			CPUUsage:  cpuVal,
-			MemUsage:  memVal,
-			DiskUsage: diskVal,
-		})
-	}
-	return history, nil
-}
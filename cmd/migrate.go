@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"system-eye/internal/store"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	migrateFrom string
+	migrateTo   string
+)
+
+// migrateCmd defines the "migrate" command.
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate a legacy history CSV file into another HistoryStore backend",
+	Long: `Reads a legacy (or current) CSV history export and writes every record into
+another HistoryStore backend, inferred from --to's extension (.csv for CSV,
+.bin/.log for the binary backend, .db/.sqlite/.sqlite3 for SQLite).
+
+Flags:
+  --from   Path to the source CSV file (required).
+  --to     Path to the destination history file (required).
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if migrateFrom == "" || migrateTo == "" {
+			fmt.Println("Both --from and --to are required.")
+			return
+		}
+		if err := runMigrate(migrateFrom, migrateTo); err != nil {
+			log.Fatalf("migrate: %v", err)
+		}
+		fmt.Printf("Migrated %s -> %s\n", migrateFrom, migrateTo)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+	migrateCmd.Flags().StringVar(&migrateFrom, "from", "", "Path to the source CSV file")
+	migrateCmd.Flags().StringVar(&migrateTo, "to", "", "Path to the destination history file")
+}
+
+// runMigrate reads every record from the legacy CSV file at fromPath and
+// appends it, in timestamp order, to the HistoryStore backend inferred from
+// toPath's extension.
+func runMigrate(fromPath, toPath string) error {
+	// OpenCSVStore creates the file if it doesn't exist, which would make a
+	// mistyped --from silently "succeed" after copying zero records. Stat it
+	// first so a bad source path fails loudly instead.
+	if _, err := os.Stat(fromPath); err != nil {
+		return fmt.Errorf("opening source CSV %q: %w", fromPath, err)
+	}
+
+	src, err := store.OpenCSVStore(fromPath)
+	if err != nil {
+		return fmt.Errorf("opening source CSV %q: %w", fromPath, err)
+	}
+	defer src.Close()
+
+	records, err := src.Query(time.Time{}, time.Time{})
+	if err != nil {
+		return fmt.Errorf("reading source CSV %q: %w", fromPath, err)
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Timestamp.Before(records[j].Timestamp)
+	})
+
+	dst, err := store.Open(store.BackendForPath(toPath), toPath)
+	if err != nil {
+		return fmt.Errorf("opening destination %q: %w", toPath, err)
+	}
+	for _, r := range records {
+		if err := dst.Append(r); err != nil {
+			dst.Close()
+			return fmt.Errorf("writing record: %w", err)
+		}
+	}
+	return dst.Close()
+}
@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"time"
 
-	"system-eye/internal/system"
+	"system-eye/internal/collector"
 
 	"github.com/guptarohit/asciigraph"
 	"github.com/spf13/cobra"
@@ -12,7 +12,7 @@ import (
 
 const (
 	maxDataPoints   = 30
-	refreshInterval = 2 * time.Second
+	monitorInterval = 2 * time.Second
 )
 
 var monitorCmd = &cobra.Command{
@@ -23,25 +23,15 @@ var monitorCmd = &cobra.Command{
 		var cpuData, memData, diskData []float64
 
 		for {
-			cpuUsage, err := system.GetCPUUsage()
+			m, err := collector.Sample()
 			if err != nil {
-				fmt.Println("Error retrieving CPU usage:", err)
-				return
-			}
-			memUsage, err := system.GetMemoryUsage()
-			if err != nil {
-				fmt.Println("Error retrieving Memory usage:", err)
-				return
-			}
-			diskUsage, err := system.GetDiskUsage("/")
-			if err != nil {
-				fmt.Println("Error retrieving Disk usage:", err)
+				fmt.Println("Error retrieving system metrics:", err)
 				return
 			}
 
-			cpuData = appendValue(cpuData, cpuUsage, maxDataPoints)
-			memData = appendValue(memData, memUsage, maxDataPoints)
-			diskData = appendValue(diskData, diskUsage, maxDataPoints)
+			cpuData = appendValue(cpuData, m.CPUUsage, maxDataPoints)
+			memData = appendValue(memData, m.MemUsage, maxDataPoints)
+			diskData = appendValue(diskData, m.DiskUsage, maxDataPoints)
 
 			fmt.Print("\033[H\033[2J")
 
@@ -61,18 +51,11 @@ var monitorCmd = &cobra.Command{
 			fmt.Println()
 			fmt.Println(diskGraph)
 
-			time.Sleep(refreshInterval)
+			time.Sleep(monitorInterval)
 		}
 	},
 }
 
-func appendValue(data []float64, value float64, maxLen int) []float64 {
-	if len(data) >= maxLen {
-		data = data[1:]
-	}
-	return append(data, value)
-}
-
 func init() {
 	rootCmd.AddCommand(monitorCmd)
 }
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"os/signal"
+	"syscall"
+
+	"system-eye/internal/exporter"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	listenAddr  string
+	metricsPath string
+	tlsCertFile string
+	tlsKeyFile  string
+)
+
+// serveCmd defines the "serve" command.
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Expose collected metrics over HTTP in Prometheus format",
+	Long: `Runs system-eye as a long-lived agent that exposes collected metrics over
+HTTP in Prometheus text exposition format (OpenMetrics is also served when a
+scraper requests it via content negotiation).
+
+It publishes gauges for CPU, memory, and disk usage, per-CPU and
+per-mountpoint breakdowns, and one gauge per registered MetricPlugin. The
+metrics reflect the same collection loop used by "system-eye dashboard",
+via the shared Collector type.
+
+Flags:
+  --listen     Address to listen on (default ":9090").
+  --path       HTTP path to serve metrics on (default "/metrics").
+  --tls-cert   Path to a TLS certificate file; enables HTTPS when set with --tls-key.
+  --tls-key    Path to a TLS private key file; enables HTTPS when set with --tls-cert.
+  --config     Path to configuration file (YAML, JSON, etc).
+  --cgroup     Scope collection to this cgroup instead of the whole host.
+  --pid        Scope collection to the cgroup(s) owning this PID instead of the whole host.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if configFile != "" {
+			if err := loadConfig(configFile); err != nil {
+				log.Printf("Failed to load config file: %v", err)
+			} else {
+				log.Printf("Loaded configuration from %s", configFile)
+			}
+		}
+		if refreshInterval != 0 {
+			config.RefreshInterval = refreshInterval
+		}
+		runServe()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringVar(&listenAddr, "listen", ":9090", "Address to listen on")
+	serveCmd.Flags().StringVar(&metricsPath, "path", "/metrics", "HTTP path to serve metrics on")
+	serveCmd.Flags().StringVar(&tlsCertFile, "tls-cert", "", "Path to a TLS certificate file")
+	serveCmd.Flags().StringVar(&tlsKeyFile, "tls-key", "", "Path to a TLS private key file")
+	serveCmd.Flags().DurationVarP(&refreshInterval, "interval", "i", 0, "Collection interval (overrides config file)")
+	serveCmd.Flags().StringVar(&configFile, "config", "", "Path to configuration file (YAML, JSON, etc)")
+	serveCmd.Flags().StringVar(&cgroupPath, "cgroup", "", "Scope collection to this cgroup instead of the whole host")
+	serveCmd.Flags().IntVar(&cgroupPID, "pid", 0, "Scope collection to the cgroup(s) owning this PID instead of the whole host")
+
+	serveCmd.MarkFlagFilename("config")
+	serveCmd.MarkFlagFilename("tls-cert")
+	serveCmd.MarkFlagFilename("tls-key")
+}
+
+// runServe starts the shared Collector and blocks serving the Prometheus
+// exporter until the process receives a termination signal.
+func runServe() {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	coll, err := newCollector(config.RefreshInterval)
+	if err != nil {
+		log.Fatalf("failed to start collector: %v", err)
+	}
+	go coll.Run(ctx, func(err error) {
+		log.Printf("Error collecting metrics: %v", err)
+	})
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- exporter.ListenAndServe(listenAddr, metricsPath, tlsCertFile, tlsKeyFile, coll)
+	}()
+
+	select {
+	case <-ctx.Done():
+		log.Println("Shutdown signal received. Exiting exporter.")
+	case err := <-errCh:
+		if err != nil {
+			log.Fatalf("exporter: %v", err)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+// Package alerts implements a threshold-based alerting engine: named rules
+// are evaluated against each collected Metric, with "for" duration hysteresis
+// so transient spikes don't fire, and crossings are emitted as AlertEvents
+// and dispatched to configured Notifiers.
+package alerts
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"system-eye/internal/collector"
+)
+
+// State describes where a rule currently sits in its lifecycle.
+type State string
+
+const (
+	// StatePending means the threshold has been crossed but the rule's "for"
+	// duration has not yet elapsed.
+	StatePending State = "pending"
+	// StateFiring means the threshold has been crossed for at least the
+	// rule's "for" duration.
+	StateFiring State = "firing"
+	// StateResolved means a previously firing (or pending) rule's condition
+	// is no longer true.
+	StateResolved State = "resolved"
+)
+
+// RuleConfig declares a single alerting rule, as loaded from the
+// configuration file.
+type RuleConfig struct {
+	Name       string        `mapstructure:"name"`
+	Metric     string        `mapstructure:"metric"`     // "cpu", "mem", "disk", or a MetricPlugin name
+	Comparison string        `mapstructure:"comparison"` // ">", ">=", "<", "<=", "==", "!="
+	Threshold  float64       `mapstructure:"threshold"`
+	For        time.Duration `mapstructure:"for"` // hysteresis: how long the condition must hold before firing
+	Severity   string        `mapstructure:"severity"`
+	Notifiers  []string      `mapstructure:"notifiers"` // names of configured notifiers to dispatch to
+}
+
+// AlertEvent records a single rule state transition.
+type AlertEvent struct {
+	Rule      string
+	Metric    string
+	Value     float64
+	Threshold float64
+	Severity  string
+	State     State
+	Timestamp time.Time
+}
+
+// String formats the event for logging, e.g. in the TUI log widget.
+func (e AlertEvent) String() string {
+	return fmt.Sprintf("[%s] rule %q %s: %s=%.2f (threshold %.2f)", e.Severity, e.Rule, e.State, e.Metric, e.Value, e.Threshold)
+}
+
+// ruleState tracks a single rule's in-progress crossing.
+type ruleState struct {
+	pendingSince time.Time
+	firing       bool
+}
+
+// RuleEvaluator evaluates a fixed set of rules against each Metric tick,
+// tracking per-rule state and dispatching AlertEvents to notifiers.
+type RuleEvaluator struct {
+	rules     []RuleConfig
+	notifiers map[string]Notifier
+
+	// OnNotifyError, if set, is called whenever dispatching an AlertEvent to
+	// a notifier fails.
+	OnNotifyError func(error)
+
+	mu     sync.Mutex
+	states map[string]*ruleState
+}
+
+// NewRuleEvaluator builds a RuleEvaluator for rules, dispatching fired events
+// to the named notifiers.
+func NewRuleEvaluator(rules []RuleConfig, notifiers map[string]Notifier) *RuleEvaluator {
+	return &RuleEvaluator{
+		rules:     rules,
+		notifiers: notifiers,
+		states:    make(map[string]*ruleState, len(rules)),
+	}
+}
+
+// metricValue extracts the named metric's value from m, falling back to its
+// plugin values.
+func metricValue(m collector.Metric, name string) (float64, bool) {
+	switch name {
+	case "cpu":
+		return m.CPUUsage, true
+	case "mem":
+		return m.MemUsage, true
+	case "disk":
+		return m.DiskUsage, true
+	default:
+		v, ok := m.Plugins[name]
+		return v, ok
+	}
+}
+
+// compare applies op to value and threshold.
+func compare(op string, value, threshold float64) (bool, error) {
+	switch op {
+	case ">":
+		return value > threshold, nil
+	case ">=":
+		return value >= threshold, nil
+	case "<":
+		return value < threshold, nil
+	case "<=":
+		return value <= threshold, nil
+	case "==":
+		return value == threshold, nil
+	case "!=":
+		return value != threshold, nil
+	default:
+		return false, fmt.Errorf("alerts: unknown comparison operator %q", op)
+	}
+}
+
+// Evaluate runs every rule against m and returns the AlertEvents produced by
+// any state transition (pending, firing, or resolved). Each returned event is
+// also dispatched to the rule's configured notifiers.
+func (e *RuleEvaluator) Evaluate(m collector.Metric) []AlertEvent {
+	var events []AlertEvent
+
+	for _, rule := range e.rules {
+		value, ok := metricValue(m, rule.Metric)
+		if !ok {
+			continue
+		}
+		crossed, err := compare(rule.Comparison, value, rule.Threshold)
+		if err != nil {
+			continue
+		}
+
+		e.mu.Lock()
+		st, exists := e.states[rule.Name]
+		if !exists {
+			st = &ruleState{}
+			e.states[rule.Name] = st
+		}
+
+		var event *AlertEvent
+		switch {
+		case crossed && !st.firing && st.pendingSince.IsZero():
+			st.pendingSince = m.Timestamp
+			event = &AlertEvent{Rule: rule.Name, Metric: rule.Metric, Value: value, Threshold: rule.Threshold, Severity: rule.Severity, State: StatePending, Timestamp: m.Timestamp}
+		case crossed && !st.firing && m.Timestamp.Sub(st.pendingSince) >= rule.For:
+			st.firing = true
+			event = &AlertEvent{Rule: rule.Name, Metric: rule.Metric, Value: value, Threshold: rule.Threshold, Severity: rule.Severity, State: StateFiring, Timestamp: m.Timestamp}
+		case !crossed && (st.firing || !st.pendingSince.IsZero()):
+			st.firing = false
+			st.pendingSince = time.Time{}
+			event = &AlertEvent{Rule: rule.Name, Metric: rule.Metric, Value: value, Threshold: rule.Threshold, Severity: rule.Severity, State: StateResolved, Timestamp: m.Timestamp}
+		}
+		e.mu.Unlock()
+
+		if event != nil {
+			events = append(events, *event)
+			e.dispatch(rule, *event)
+		}
+	}
+
+	return events
+}
+
+// dispatch sends event to every notifier named by rule, logging (but not
+// failing on) any notifier error. Each notifier runs on its own goroutine,
+// the same way Collector.Run reports errors to its caller, so a slow or
+// unreachable notifier (webhook, SMTP, command) never blocks Evaluate's
+// caller.
+func (e *RuleEvaluator) dispatch(rule RuleConfig, event AlertEvent) {
+	for _, name := range rule.Notifiers {
+		notifier, ok := e.notifiers[name]
+		if !ok {
+			continue
+		}
+		go func() {
+			if err := notifier.Notify(event); err != nil && e.OnNotifyError != nil {
+				e.OnNotifyError(fmt.Errorf("notifier %q: %w", name, err))
+			}
+		}()
+	}
+}
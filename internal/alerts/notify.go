@@ -0,0 +1,173 @@
+package alerts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// webhookTimeout bounds how long a single webhook POST may take, so a slow
+// or unreachable endpoint fails instead of hanging indefinitely.
+const webhookTimeout = 10 * time.Second
+
+// Notifier delivers an AlertEvent to some external destination.
+type Notifier interface {
+	Notify(event AlertEvent) error
+}
+
+// NotifierConfig configures a single named notifier, as loaded from the
+// configuration file. Type selects which fields apply:
+//
+//	stdout  - no further fields.
+//	file    - Path.
+//	webhook - URL (JSON POST of the AlertEvent).
+//	email   - SMTP.
+//	command - Command (run via the shell, with the event available through
+//	          SYSTEM_EYE_ALERT_* environment variables).
+type NotifierConfig struct {
+	Type    string     `mapstructure:"type"`
+	Path    string     `mapstructure:"path"`
+	URL     string     `mapstructure:"url"`
+	Command string     `mapstructure:"command"`
+	SMTP    SMTPConfig `mapstructure:"smtp"`
+}
+
+// SMTPConfig holds the settings needed to send an email notification.
+type SMTPConfig struct {
+	Host     string   `mapstructure:"host"`
+	Port     int      `mapstructure:"port"`
+	From     string   `mapstructure:"from"`
+	To       []string `mapstructure:"to"`
+	Username string   `mapstructure:"username"`
+	Password string   `mapstructure:"password"`
+}
+
+// BuildNotifiers constructs a Notifier for every entry in configs, keyed by
+// its configuration name.
+func BuildNotifiers(configs map[string]NotifierConfig) (map[string]Notifier, error) {
+	notifiers := make(map[string]Notifier, len(configs))
+	for name, cfg := range configs {
+		notifier, err := buildNotifier(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("alerts: notifier %q: %w", name, err)
+		}
+		notifiers[name] = notifier
+	}
+	return notifiers, nil
+}
+
+func buildNotifier(cfg NotifierConfig) (Notifier, error) {
+	switch cfg.Type {
+	case "stdout":
+		return StdoutNotifier{}, nil
+	case "file":
+		if cfg.Path == "" {
+			return nil, fmt.Errorf("file notifier requires a path")
+		}
+		return FileNotifier{Path: cfg.Path}, nil
+	case "webhook":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("webhook notifier requires a url")
+		}
+		return WebhookNotifier{URL: cfg.URL}, nil
+	case "email":
+		return EmailNotifier{SMTP: cfg.SMTP}, nil
+	case "command":
+		if cfg.Command == "" {
+			return nil, fmt.Errorf("command notifier requires a command")
+		}
+		return CommandNotifier{Command: cfg.Command}, nil
+	default:
+		return nil, fmt.Errorf("unknown notifier type %q", cfg.Type)
+	}
+}
+
+// StdoutNotifier prints the event to standard output.
+type StdoutNotifier struct{}
+
+func (StdoutNotifier) Notify(event AlertEvent) error {
+	_, err := fmt.Println(event.String())
+	return err
+}
+
+// FileNotifier appends the event to a log file.
+type FileNotifier struct {
+	Path string
+}
+
+func (n FileNotifier) Notify(event AlertEvent) error {
+	f, err := os.OpenFile(n.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintln(f, event.String())
+	return err
+}
+
+// WebhookNotifier POSTs the event as JSON to a URL.
+type WebhookNotifier struct {
+	URL string
+}
+
+var webhookClient = &http.Client{Timeout: webhookTimeout}
+
+func (n WebhookNotifier) Notify(event AlertEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	resp, err := webhookClient.Post(n.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook notifier: unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// EmailNotifier sends the event over SMTP.
+type EmailNotifier struct {
+	SMTP SMTPConfig
+}
+
+func (n EmailNotifier) Notify(event AlertEvent) error {
+	addr := fmt.Sprintf("%s:%d", n.SMTP.Host, n.SMTP.Port)
+	var auth smtp.Auth
+	if n.SMTP.Username != "" {
+		auth = smtp.PlainAuth("", n.SMTP.Username, n.SMTP.Password, n.SMTP.Host)
+	}
+
+	subject := fmt.Sprintf("system-eye alert: %s (%s)", event.Rule, event.State)
+	msg := fmt.Sprintf("Subject: %s\r\n\r\n%s\r\n", subject, event.String())
+
+	return smtp.SendMail(addr, auth, n.SMTP.From, n.SMTP.To, []byte(msg))
+}
+
+// CommandNotifier runs a shell command hook for the event, passing its
+// fields through SYSTEM_EYE_ALERT_* environment variables.
+type CommandNotifier struct {
+	Command string
+}
+
+func (n CommandNotifier) Notify(event AlertEvent) error {
+	cmd := exec.Command("sh", "-c", n.Command)
+	cmd.Env = append(os.Environ(),
+		"SYSTEM_EYE_ALERT_RULE="+event.Rule,
+		"SYSTEM_EYE_ALERT_METRIC="+event.Metric,
+		"SYSTEM_EYE_ALERT_STATE="+string(event.State),
+		"SYSTEM_EYE_ALERT_SEVERITY="+event.Severity,
+		fmt.Sprintf("SYSTEM_EYE_ALERT_VALUE=%.4f", event.Value),
+		fmt.Sprintf("SYSTEM_EYE_ALERT_THRESHOLD=%.4f", event.Threshold),
+	)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,340 @@
+// Package cgroup reads CPU, memory, and block I/O accounting for a single
+// cgroup v1 or v2 hierarchy, mirroring the approach Arvados crunchstat uses
+// to monitor a container or systemd service rather than the whole host.
+package cgroup
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hostRoot is the conventional mount point for the cgroup filesystem.
+const hostRoot = "/sys/fs/cgroup"
+
+// Usage holds a cgroup's resource counters, with CPU and block I/O expressed
+// as rates computed against the previous call to Reader.Sample.
+type Usage struct {
+	Timestamp time.Time
+
+	// CPUPercent is the cgroup's CPU time consumed since the previous
+	// sample, as a percentage of one core (so 250.0 means 2.5 cores busy).
+	CPUPercent float64
+
+	MemUsageBytes uint64
+	MemCacheBytes uint64
+	MemRSSBytes   uint64
+	MemSwapBytes  uint64
+	MemPgMajFault uint64
+
+	BlkIOReadBytesRate  float64
+	BlkIOWriteBytesRate float64
+}
+
+// Reader samples a single cgroup's accounting files. Concurrent calls to
+// Sample are serialized by an internal mutex.
+type Reader struct {
+	version int // 1 or 2
+
+	cpuPath    string // directory containing cpuacct.usage (v1) or cpu.stat (v2)
+	memoryPath string // directory containing memory.stat
+	blkioPath  string // directory containing blkio.io_service_bytes (v1) or io.stat (v2)
+
+	mu          sync.Mutex
+	prevAt      time.Time
+	prevCPUNs   uint64
+	prevRead    uint64
+	prevWritten uint64
+}
+
+// NewReader resolves cgroupPath (relative to the host's cgroup mount, e.g.
+// "/docker/<id>" or "/system.slice/foo.service") against whichever cgroup
+// version the host runs, and returns a Reader scoped to it.
+func NewReader(cgroupPath string) (*Reader, error) {
+	if isV2Host() {
+		dir := filepath.Join(hostRoot, cgroupPath)
+		return readerForV2(dir, dir, dir)
+	}
+	return readerForV1(map[string]string{
+		"cpuacct": filepath.Join(hostRoot, "cpu,cpuacct", cgroupPath),
+		"memory":  filepath.Join(hostRoot, "memory", cgroupPath),
+		"blkio":   filepath.Join(hostRoot, "blkio", cgroupPath),
+	})
+}
+
+// ReaderForPID resolves the cgroup(s) pid belongs to, by reading
+// /proc/<pid>/cgroup, and returns a Reader scoped to them.
+func ReaderForPID(pid int) (*Reader, error) {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var v2Path string
+	v1Paths := map[string]string{}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Each line is "hierarchy-ID:controller-list:path", e.g.
+		// "4:memory:/docker/abc" (v1) or "0::/user.slice/foo.service" (v2).
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		controllers, path := parts[1], parts[2]
+		if controllers == "" {
+			v2Path = path
+			continue
+		}
+		for _, c := range strings.Split(controllers, ",") {
+			v1Paths[c] = path
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if isV2Host() && v2Path != "" {
+		dir := filepath.Join(hostRoot, v2Path)
+		return readerForV2(dir, dir, dir)
+	}
+	return readerForV1(map[string]string{
+		"cpuacct": filepath.Join(hostRoot, "cpu,cpuacct", v1Paths["cpuacct"]),
+		"memory":  filepath.Join(hostRoot, "memory", v1Paths["memory"]),
+		"blkio":   filepath.Join(hostRoot, "blkio", v1Paths["blkio"]),
+	})
+}
+
+func isV2Host() bool {
+	_, err := os.Stat(filepath.Join(hostRoot, "cgroup.controllers"))
+	return err == nil
+}
+
+func readerForV1(paths map[string]string) (*Reader, error) {
+	return &Reader{
+		version:    1,
+		cpuPath:    paths["cpuacct"],
+		memoryPath: paths["memory"],
+		blkioPath:  paths["blkio"],
+	}, nil
+}
+
+func readerForV2(cpuPath, memoryPath, blkioPath string) (*Reader, error) {
+	return &Reader{
+		version:    2,
+		cpuPath:    cpuPath,
+		memoryPath: memoryPath,
+		blkioPath:  blkioPath,
+	}, nil
+}
+
+// Sample reads the cgroup's current counters and returns a Usage with rates
+// computed against the previous call. The first call after construction
+// reports a zero CPU and I/O rate, since there is no prior sample to diff
+// against.
+func (r *Reader) Sample() (Usage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cpuNs, err := r.readCPUNanos()
+	if err != nil {
+		return Usage{}, err
+	}
+	memStat, err := r.readMemory()
+	if err != nil {
+		return Usage{}, err
+	}
+	readBytes, writtenBytes, err := r.readBlkIO()
+	if err != nil {
+		return Usage{}, err
+	}
+
+	now := time.Now()
+	elapsed := now.Sub(r.prevAt).Seconds()
+
+	usage := Usage{
+		Timestamp:     now,
+		MemUsageBytes: memStat.usage,
+		MemCacheBytes: memStat.cache,
+		MemRSSBytes:   memStat.rss,
+		MemSwapBytes:  memStat.swap,
+		MemPgMajFault: memStat.pgMajFault,
+	}
+	if !r.prevAt.IsZero() && elapsed > 0 {
+		// CPU time is in nanoseconds; express the delta as a percentage of
+		// one core (1 core-second of CPU time per elapsed second == 100%).
+		usage.CPUPercent = rate(cpuNs, r.prevCPUNs, elapsed) / 1e9 * 100
+		usage.BlkIOReadBytesRate = rate(readBytes, r.prevRead, elapsed)
+		usage.BlkIOWriteBytesRate = rate(writtenBytes, r.prevWritten, elapsed)
+	}
+
+	r.prevAt = now
+	r.prevCPUNs = cpuNs
+	r.prevRead = readBytes
+	r.prevWritten = writtenBytes
+
+	return usage, nil
+}
+
+// rate computes (current-previous)/elapsedSeconds, clamping to 0 if the
+// counter appears to have reset (e.g. the cgroup was recreated).
+func rate(current, previous uint64, elapsedSeconds float64) float64 {
+	if current < previous {
+		return 0
+	}
+	return float64(current-previous) / elapsedSeconds
+}
+
+func (r *Reader) readCPUNanos() (uint64, error) {
+	if r.version == 1 {
+		raw, err := os.ReadFile(filepath.Join(r.cpuPath, "cpuacct.usage"))
+		if err != nil {
+			return 0, err
+		}
+		return strconv.ParseUint(strings.TrimSpace(string(raw)), 10, 64)
+	}
+
+	fields, err := readKeyedFile(filepath.Join(r.cpuPath, "cpu.stat"))
+	if err != nil {
+		return 0, err
+	}
+	// cpu.stat reports usage_usec in microseconds.
+	return fields["usage_usec"] * 1000, nil
+}
+
+type memoryStat struct {
+	usage      uint64
+	cache      uint64
+	rss        uint64
+	swap       uint64
+	pgMajFault uint64
+}
+
+func (r *Reader) readMemory() (memoryStat, error) {
+	fields, err := readKeyedFile(filepath.Join(r.memoryPath, "memory.stat"))
+	if err != nil {
+		return memoryStat{}, err
+	}
+
+	var stat memoryStat
+	if r.version == 1 {
+		stat.cache = fields["cache"]
+		stat.rss = fields["rss"]
+		stat.swap = fields["swap"]
+		stat.pgMajFault = fields["pgmajfault"]
+		stat.usage = stat.cache + stat.rss
+		if raw, err := os.ReadFile(filepath.Join(r.memoryPath, "memory.usage_in_bytes")); err == nil {
+			stat.usage, _ = strconv.ParseUint(strings.TrimSpace(string(raw)), 10, 64)
+		}
+	} else {
+		// v2's memory.stat uses "file" and "anon" in place of "cache"/"rss".
+		stat.cache = fields["file"]
+		stat.rss = fields["anon"]
+		stat.swap = fields["swapcached"]
+		stat.pgMajFault = fields["pgmajfault"]
+		if raw, err := os.ReadFile(filepath.Join(r.memoryPath, "memory.current")); err == nil {
+			stat.usage, _ = strconv.ParseUint(strings.TrimSpace(string(raw)), 10, 64)
+		}
+	}
+	return stat, nil
+}
+
+func (r *Reader) readBlkIO() (read, written uint64, err error) {
+	if r.version == 1 {
+		return readBlkioServiceBytes(filepath.Join(r.blkioPath, "blkio.io_service_bytes"))
+	}
+	return readIOStat(filepath.Join(r.blkioPath, "io.stat"))
+}
+
+// readBlkioServiceBytes parses blkio.io_service_bytes, whose lines look like
+// "8:0 Read 123456" / "8:0 Write 654321" / "8:0 Total 777777", summed across
+// every device.
+func readBlkioServiceBytes(path string) (read, written uint64, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[1] {
+		case "Read":
+			read += value
+		case "Write":
+			written += value
+		}
+	}
+	return read, written, scanner.Err()
+}
+
+// readIOStat parses cgroup v2's io.stat, whose lines look like
+// "253:0 rbytes=123 wbytes=456 rios=1 wios=2 dbytes=0 dios=0", summed across
+// every device.
+func readIOStat(path string) (read, written uint64, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		for _, field := range strings.Fields(scanner.Text()) {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				continue
+			}
+			n, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			switch key {
+			case "rbytes":
+				read += n
+			case "wbytes":
+				written += n
+			}
+		}
+	}
+	return read, written, scanner.Err()
+}
+
+// readKeyedFile parses a file of "key value" lines, one per line, as found
+// in memory.stat and cpu.stat under both cgroup versions.
+func readKeyedFile(path string) (map[string]uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fields := map[string]uint64{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) != 2 {
+			continue
+		}
+		value, err := strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		fields[parts[0]] = value
+	}
+	return fields, scanner.Err()
+}
@@ -0,0 +1,297 @@
+// Package collector implements the shared metric-collection loop used by both
+// the interactive TUI dashboard and the Prometheus HTTP exporter, so that
+// every consumer of system-eye's metrics reads the exact same samples.
+package collector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"system-eye/internal/cgroup"
+	"system-eye/internal/system"
+)
+
+// Metric represents system metrics along with a timestamp.
+type Metric struct {
+	Timestamp time.Time
+	CPUUsage  float64
+	MemUsage  float64
+	DiskUsage float64
+
+	// PerCoreUsage holds the usage percentage of each logical CPU core,
+	// indexed by core number.
+	PerCoreUsage []float64
+	// LoadAvg holds the 1/5/15 minute load averages.
+	LoadAvg system.LoadAverage
+	// NetIO holds per-interface network throughput.
+	NetIO []system.NetIOStat
+	// DiskIO holds per-device disk throughput and IOPS.
+	DiskIO []system.DiskIOStat
+	// Temps holds the readings from every temperature sensor the host exposes.
+	Temps []system.Temperature
+	// CPUTimes holds the cumulative user/system/iowait/irq/softirq seconds
+	// for each logical CPU core.
+	CPUTimes []system.CPUTimes
+
+	// CgroupName labels which cgroup Cgroup was sampled from, when the
+	// Collector is running in scoped mode (see NewScoped). Empty otherwise.
+	CgroupName string
+	// Cgroup holds CPU, memory, and block I/O accounting for the cgroup
+	// named by CgroupName. Nil unless the Collector is running scoped.
+	Cgroup *cgroup.Usage
+
+	// Plugins holds the latest value reported by each registered MetricPlugin,
+	// keyed by plugin name.
+	Plugins map[string]float64
+}
+
+// MetricPlugin defines an interface for extensible metric plugins.
+type MetricPlugin interface {
+	// Name returns the plugin's name.
+	Name() string
+	// Fetch returns the plugin's metric value.
+	Fetch() (float64, error)
+}
+
+// ScopedPlugin is implemented by a MetricPlugin that wants to report on the
+// same cgroup the Collector is scoped to (see NewScoped), rather than always
+// reading whole-host state.
+type ScopedPlugin interface {
+	MetricPlugin
+	// UseCgroup is called once, before the first Fetch, with the label the
+	// Collector was scoped under (the path given to NewScoped). That label is
+	// the cgroup path for a --cgroup scope, but "pid <N>" for a --pid scope,
+	// not the resolved cgroup path. It is never called when the Collector is
+	// running unscoped against the whole host.
+	UseCgroup(path string)
+}
+
+// pluginRegistry holds all registered plugins.
+var (
+	pluginRegistryMu sync.RWMutex
+	pluginRegistry   []MetricPlugin
+)
+
+// RegisterPlugin adds a new plugin to the registry.
+func RegisterPlugin(p MetricPlugin) {
+	pluginRegistryMu.Lock()
+	defer pluginRegistryMu.Unlock()
+	pluginRegistry = append(pluginRegistry, p)
+}
+
+// Plugins returns a snapshot of the currently registered plugins.
+func Plugins() []MetricPlugin {
+	pluginRegistryMu.RLock()
+	defer pluginRegistryMu.RUnlock()
+	out := make([]MetricPlugin, len(pluginRegistry))
+	copy(out, pluginRegistry)
+	return out
+}
+
+// Sample collects a single Metric synchronously, fetching CPU, memory, disk,
+// and every registered MetricPlugin. Errors fetching an individual plugin are
+// ignored; the plugin is simply absent from the returned Metric.
+func Sample() (Metric, error) {
+	// A single cpu.Percent(time.Second, true) call both blocks for the
+	// sample's 1s window and gives us every core's reading; taking the
+	// aggregate as their mean (rather than a second, separate 1s call) keeps
+	// CPUUsage and PerCoreUsage describing the same window instead of two
+	// consecutive ones, and keeps Sample's total latency at ~1s.
+	perCore, err := system.GetPerCPUUsage()
+	if err != nil {
+		return Metric{}, err
+	}
+	cpuUsage := meanFloat64(perCore)
+
+	memUsage, err := system.GetMemoryUsage()
+	if err != nil {
+		return Metric{}, err
+	}
+	diskUsage, err := system.GetDiskUsage("/")
+	if err != nil {
+		return Metric{}, err
+	}
+
+	plugins := Plugins()
+	values := make(map[string]float64, len(plugins))
+	for _, p := range plugins {
+		v, err := p.Fetch()
+		if err != nil {
+			continue
+		}
+		values[p.Name()] = v
+	}
+
+	// The remaining fields are enrichments rather than core metrics: a
+	// platform that can't report them (e.g. no sensors, no load average on
+	// some OSes) simply leaves the corresponding field at its zero value
+	// rather than failing the whole sample.
+	loadAvg, _ := system.GetLoadAverage()
+	netIO, _ := system.GetNetIOCounters()
+	diskIO, _ := system.GetDiskIOCounters()
+	temps, _ := system.GetTemperatures()
+	cpuTimes, _ := system.GetCPUTimes()
+
+	return Metric{
+		Timestamp:    time.Now(),
+		CPUUsage:     cpuUsage,
+		MemUsage:     memUsage,
+		DiskUsage:    diskUsage,
+		PerCoreUsage: perCore,
+		LoadAvg:      loadAvg,
+		NetIO:        netIO,
+		DiskIO:       diskIO,
+		Temps:        temps,
+		CPUTimes:     cpuTimes,
+		Plugins:      values,
+	}, nil
+}
+
+// meanFloat64 returns the arithmetic mean of values, or 0 for an empty slice.
+func meanFloat64(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// Collector periodically samples system metrics and broadcasts them to any
+// subscribers. The TUI dashboard and the HTTP exporter each drive a Collector
+// with the same sampling logic, so they never disagree about what was
+// observed at a given tick.
+type Collector struct {
+	mu     sync.RWMutex
+	latest Metric
+
+	subMu sync.Mutex
+	subs  map[chan Metric]struct{}
+
+	retune chan time.Duration
+
+	cgroupName   string
+	cgroupReader *cgroup.Reader
+}
+
+// New creates a Collector that samples metrics every interval.
+func New(interval time.Duration) *Collector {
+	c := &Collector{
+		subs:   make(map[chan Metric]struct{}),
+		retune: make(chan time.Duration, 1),
+	}
+	c.retune <- interval
+	return c
+}
+
+// NewScoped creates a Collector that additionally reports CPU, memory, and
+// I/O accounting for a single cgroup (see cgroup.NewReader/ReaderForPID),
+// labeled path. Every registered ScopedPlugin is notified of path so it can
+// report on the same scope.
+func NewScoped(interval time.Duration, reader *cgroup.Reader, path string) *Collector {
+	c := New(interval)
+	c.cgroupReader = reader
+	c.cgroupName = path
+
+	for _, p := range Plugins() {
+		if sp, ok := p.(ScopedPlugin); ok {
+			sp.UseCgroup(path)
+		}
+	}
+	return c
+}
+
+// SetInterval changes the sampling interval of a running Run loop.
+func (c *Collector) SetInterval(d time.Duration) {
+	// Drain a pending, not-yet-applied change so the latest call always wins.
+	select {
+	case <-c.retune:
+	default:
+	}
+	c.retune <- d
+}
+
+// Latest returns the most recently collected sample. It is the zero Metric
+// until the first successful sample.
+func (c *Collector) Latest() Metric {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.latest
+}
+
+// Subscribe returns a channel that receives every sample collected from this
+// point on, and an unsubscribe function that must be called to release it.
+// The channel is buffered; a slow consumer only misses samples, it never
+// blocks the collection loop.
+func (c *Collector) Subscribe() (<-chan Metric, func()) {
+	ch := make(chan Metric, 8)
+	c.subMu.Lock()
+	c.subs[ch] = struct{}{}
+	c.subMu.Unlock()
+
+	unsubscribe := func() {
+		c.subMu.Lock()
+		delete(c.subs, ch)
+		c.subMu.Unlock()
+		close(ch)
+	}
+	return ch, unsubscribe
+}
+
+func (c *Collector) broadcast(m Metric) {
+	c.subMu.Lock()
+	defer c.subMu.Unlock()
+	for ch := range c.subs {
+		select {
+		case ch <- m:
+		default:
+			// Drop the sample for this subscriber rather than block the loop.
+		}
+	}
+}
+
+// Run drives the collection loop until ctx is cancelled, calling onError (if
+// non-nil) whenever a sample fails. Successful samples update Latest and are
+// sent to every Subscribe channel.
+func (c *Collector) Run(ctx context.Context, onError func(error)) {
+	interval := <-c.retune
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case interval = <-c.retune:
+			ticker.Reset(interval)
+		case <-ticker.C:
+			m, err := Sample()
+			if err != nil {
+				if onError != nil {
+					onError(err)
+				}
+				continue
+			}
+
+			if c.cgroupReader != nil {
+				if usage, err := c.cgroupReader.Sample(); err != nil {
+					if onError != nil {
+						onError(err)
+					}
+				} else {
+					m.CgroupName = c.cgroupName
+					m.Cgroup = &usage
+				}
+			}
+
+			c.mu.Lock()
+			c.latest = m
+			c.mu.Unlock()
+
+			c.broadcast(m)
+		}
+	}
+}
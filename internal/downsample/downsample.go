@@ -0,0 +1,133 @@
+// Package downsample reduces long time series to a target number of points
+// for rendering on a fixed-width chart, without the "spikes averaged away"
+// problem of naive mean/min/max windowing.
+package downsample
+
+// LTTB reduces data to threshold points using the Largest-Triangle-Three-
+// Buckets algorithm: the first and last points are always kept, and the
+// remaining points are split into threshold-2 equal-size buckets. From each
+// bucket, LTTB keeps the point that forms the largest triangle with the
+// previously selected point and the average of the next bucket, which tends
+// to preserve peaks and troughs that a simple average would smooth over.
+//
+// If data already has threshold or fewer points, it is returned unchanged.
+func LTTB(data []float64, threshold int) []float64 {
+	n := len(data)
+	if threshold <= 0 || n <= threshold || threshold <= 2 {
+		return data
+	}
+
+	out := make([]float64, 0, threshold)
+	out = append(out, data[0])
+
+	// bucketSize is the average number of points per bucket, excluding the
+	// pinned first and last points.
+	bucketSize := float64(n-2) / float64(threshold-2)
+
+	selected := 0 // index into data of the previously selected point
+	for i := 0; i < threshold-2; i++ {
+		// The next bucket's average point, used as the triangle's third
+		// vertex for this bucket's selection.
+		nextStart := int(float64(i+1)*bucketSize) + 1
+		nextEnd := int(float64(i+2)*bucketSize) + 1
+		if nextEnd > n {
+			nextEnd = n
+		}
+		if nextStart >= nextEnd {
+			nextStart = nextEnd - 1
+		}
+		var avgX, avgY float64
+		for j := nextStart; j < nextEnd; j++ {
+			avgX += float64(j)
+			avgY += data[j]
+		}
+		count := float64(nextEnd - nextStart)
+		avgX /= count
+		avgY /= count
+
+		bucketStart := int(float64(i)*bucketSize) + 1
+		bucketEnd := int(float64(i+1)*bucketSize) + 1
+		if bucketEnd > n {
+			bucketEnd = n
+		}
+
+		ax, ay := float64(selected), data[selected]
+		bestIdx := bucketStart
+		bestArea := -1.0
+		for j := bucketStart; j < bucketEnd; j++ {
+			bx, by := float64(j), data[j]
+			area := triangleArea(ax, ay, bx, by, avgX, avgY)
+			if area > bestArea {
+				bestArea = area
+				bestIdx = j
+			}
+		}
+
+		out = append(out, data[bestIdx])
+		selected = bestIdx
+	}
+
+	out = append(out, data[n-1])
+	return out
+}
+
+// triangleArea returns twice the unsigned area of the triangle formed by the
+// three points, which is all LTTB needs to compare candidates.
+func triangleArea(ax, ay, bx, by, cx, cy float64) float64 {
+	area := ax*(by-cy) + bx*(cy-ay) + cx*(ay-by)
+	if area < 0 {
+		return -area
+	}
+	return area
+}
+
+// MinMaxRollup reduces data to roughly threshold points by splitting it into
+// equal-size buckets and keeping both the minimum and maximum of each
+// bucket, in the order they occur. This is cheaper than LTTB and guarantees
+// every spike is visible, at the cost of distorting the apparent shape of
+// the series, so it suits alert-style "did this ever cross X" views rather
+// than trend charts.
+//
+// If data already has threshold or fewer points, it is returned unchanged.
+func MinMaxRollup(data []float64, threshold int) []float64 {
+	n := len(data)
+	if threshold <= 0 || n <= threshold {
+		return data
+	}
+
+	buckets := threshold / 2
+	if buckets < 1 {
+		buckets = 1
+	}
+	bucketSize := float64(n) / float64(buckets)
+
+	out := make([]float64, 0, buckets*2)
+	for i := 0; i < buckets; i++ {
+		start := int(float64(i) * bucketSize)
+		end := int(float64(i+1) * bucketSize)
+		if end > n {
+			end = n
+		}
+		if start >= end {
+			continue
+		}
+
+		minVal, maxVal := data[start], data[start]
+		minIdx, maxIdx := start, start
+		for j := start + 1; j < end; j++ {
+			if data[j] < minVal {
+				minVal, minIdx = data[j], j
+			}
+			if data[j] > maxVal {
+				maxVal, maxIdx = data[j], j
+			}
+		}
+
+		if minIdx <= maxIdx {
+			out = append(out, minVal, maxVal)
+		} else {
+			out = append(out, maxVal, minVal)
+		}
+	}
+	return out
+}
@@ -0,0 +1,165 @@
+// Package exporter exposes system-eye's collected metrics over HTTP in
+// Prometheus exposition format (and, via the same handler, OpenMetrics
+// through content negotiation).
+package exporter
+
+import (
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"system-eye/internal/collector"
+	"system-eye/internal/system"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes every metric exposed by this exporter.
+const namespace = "system_eye"
+
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+// sanitizeName converts an arbitrary plugin name into a valid Prometheus
+// metric name fragment.
+func sanitizeName(name string) string {
+	sanitized := invalidNameChars.ReplaceAllString(name, "_")
+	sanitized = strings.Trim(sanitized, "_")
+	if sanitized == "" {
+		sanitized = "plugin"
+	}
+	return sanitized
+}
+
+// Handler returns an http.Handler that serves c's collected metrics on
+// scrape, in Prometheus text format. Each request reads c.Latest(), the same
+// last tick the TUI dashboard reads, so plugin/per-core values are exactly
+// what the collection loop last saw. The per-mountpoint disk gauges are the
+// exception: they are read fresh on every scrape via GetDiskUsageAll.
+func Handler(c *collector.Collector) http.Handler {
+	reg := prometheus.NewRegistry()
+
+	cpuGauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "cpu_usage_percent",
+		Help:      "Total CPU usage percentage.",
+	})
+	memGauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "memory_usage_percent",
+		Help:      "Memory usage percentage.",
+	})
+	diskGauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "disk_usage_percent",
+		Help:      "Disk usage percentage for the root filesystem.",
+	})
+	perCPUGauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "cpu_core_usage_percent",
+		Help:      "Per-core CPU usage percentage.",
+	}, []string{"core"})
+	perMountGauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "disk_mount_usage_percent",
+		Help:      "Disk usage percentage per mountpoint.",
+	}, []string{"mountpoint"})
+	cgroupCPUGauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "cgroup_cpu_percent",
+		Help:      "Cgroup CPU usage as a percentage of one core, when scoped to a cgroup.",
+	}, []string{"cgroup"})
+	cgroupMemGauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "cgroup_memory_usage_bytes",
+		Help:      "Cgroup memory usage in bytes, when scoped to a cgroup.",
+	}, []string{"cgroup"})
+	cpuTimeGauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "cpu_time_seconds_total",
+		Help:      "Cumulative per-core CPU time in seconds, broken down by mode.",
+	}, []string{"core", "mode"})
+	// pluginGauges is read and written by every scrape, which can run
+	// concurrently (e.g. an HA Prometheus pair, or a manual curl mid-scrape),
+	// so access is guarded by pluginGaugesMu.
+	var pluginGaugesMu sync.Mutex
+	pluginGauges := map[string]prometheus.Gauge{}
+
+	reg.MustRegister(cpuGauge, memGauge, diskGauge, perCPUGauge, perMountGauge, cgroupCPUGauge, cgroupMemGauge, cpuTimeGauge)
+
+	collectFn := func() {
+		m := c.Latest()
+		cpuGauge.Set(m.CPUUsage)
+		memGauge.Set(m.MemUsage)
+		diskGauge.Set(m.DiskUsage)
+
+		// Reset the per-label vecs before repopulating them: a core or mount
+		// that disappears between scrapes (e.g. a CPU hot-unplugged, a
+		// filesystem unmounted) would otherwise keep exporting its last known
+		// value forever.
+		perCPUGauge.Reset()
+		perMountGauge.Reset()
+		cpuTimeGauge.Reset()
+
+		for i, pct := range m.PerCoreUsage {
+			perCPUGauge.WithLabelValues(strconv.Itoa(i)).Set(pct)
+		}
+		if mounts, err := system.GetDiskUsageAll(); err == nil {
+			for mountpoint, pct := range mounts {
+				perMountGauge.WithLabelValues(mountpoint).Set(pct)
+			}
+		}
+		for _, t := range m.CPUTimes {
+			cpuTimeGauge.WithLabelValues(t.Core, "user").Set(t.User)
+			cpuTimeGauge.WithLabelValues(t.Core, "system").Set(t.System)
+			cpuTimeGauge.WithLabelValues(t.Core, "iowait").Set(t.Iowait)
+			cpuTimeGauge.WithLabelValues(t.Core, "irq").Set(t.Irq)
+			cpuTimeGauge.WithLabelValues(t.Core, "softirq").Set(t.Softirq)
+		}
+		if m.Cgroup != nil {
+			cgroupCPUGauge.WithLabelValues(m.CgroupName).Set(m.Cgroup.CPUPercent)
+			cgroupMemGauge.WithLabelValues(m.CgroupName).Set(float64(m.Cgroup.MemUsageBytes))
+		}
+
+		pluginGaugesMu.Lock()
+		defer pluginGaugesMu.Unlock()
+		for name, value := range m.Plugins {
+			key := sanitizeName(name)
+			g, ok := pluginGauges[key]
+			if !ok {
+				g = prometheus.NewGauge(prometheus.GaugeOpts{
+					Namespace: namespace,
+					Subsystem: "plugin",
+					Name:      key,
+					Help:      "Value reported by the " + name + " MetricPlugin.",
+				})
+				reg.MustRegister(g)
+				pluginGauges[key] = g
+			}
+			g.Set(value)
+		}
+	}
+
+	handler := promhttp.HandlerFor(reg, promhttp.HandlerOpts{EnableOpenMetrics: true})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		collectFn()
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// ListenAndServe starts the exporter's HTTP server, serving on path and
+// listening on addr. If certFile and keyFile are both non-empty, it serves
+// over TLS.
+func ListenAndServe(addr, path, certFile, keyFile string, c *collector.Collector) error {
+	mux := http.NewServeMux()
+	mux.Handle(path, Handler(c))
+
+	log.Printf("exporter: serving metrics on %s%s", addr, path)
+	if certFile != "" && keyFile != "" {
+		return http.ListenAndServeTLS(addr, certFile, keyFile, mux)
+	}
+	return http.ListenAndServe(addr, mux)
+}
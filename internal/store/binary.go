@@ -0,0 +1,200 @@
+package store
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// binaryFooterMagic marks the fixed-size trailer written at the very end of
+// a BinaryStore file: 4 magic bytes followed by the 8-byte big-endian byte
+// offset of the footer index. The index itself is a gob-encoded
+// []binaryIndexEntry, letting Query seek directly to a time range instead of
+// decoding every record.
+var binaryFooterMagic = [4]byte{'s', 'e', 'h', '1'}
+
+const binaryTrailerSize = len(binaryFooterMagic) + 8
+
+// binaryIndexEntry records where one Record starts, for the footer index.
+type binaryIndexEntry struct {
+	TimestampNano int64
+	Offset        int64
+}
+
+// BinaryStore is a compact append-only log of length-prefixed gob-encoded
+// Records, with a footer index of (timestamp, offset) pairs written on
+// Close so a later Open can seek directly to a time range.
+type BinaryStore struct {
+	path  string
+	file  *os.File
+	index []binaryIndexEntry // sorted by TimestampNano
+}
+
+// OpenBinaryStore opens (creating if necessary) a binary history log at
+// path, appending to it if it already holds data. Any existing footer is
+// read into memory and then truncated off disk; it is rewritten on Close.
+func OpenBinaryStore(path string) (*BinaryStore, error) {
+	index, dataLen, err := readBinaryFooter(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Truncate(dataLen); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if _, err := f.Seek(dataLen, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &BinaryStore{path: path, file: f, index: index}, nil
+}
+
+// readBinaryFooter reads path's footer (if any) and returns the index plus
+// the byte length of the data section that precedes it.
+func readBinaryFooter(path string) ([]binaryIndexEntry, int64, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	if info.Size() < int64(binaryTrailerSize) {
+		// Too small to hold a footer: treat the whole file as data (e.g. it
+		// is empty, or a previous process crashed before Close ran).
+		return nil, info.Size(), nil
+	}
+
+	trailer := make([]byte, binaryTrailerSize)
+	if _, err := f.ReadAt(trailer, info.Size()-int64(binaryTrailerSize)); err != nil {
+		return nil, 0, err
+	}
+	if !bytes.Equal(trailer[:4], binaryFooterMagic[:]) {
+		return nil, info.Size(), nil
+	}
+	footerOffset := int64(binary.BigEndian.Uint64(trailer[4:]))
+
+	if _, err := f.Seek(footerOffset, io.SeekStart); err != nil {
+		return nil, 0, err
+	}
+	var index []binaryIndexEntry
+	if err := gob.NewDecoder(f).Decode(&index); err != nil {
+		return nil, 0, err
+	}
+	return index, footerOffset, nil
+}
+
+// Append writes r as a length-prefixed gob record and extends the in-memory
+// index; the on-disk footer is not rewritten until Close.
+func (s *BinaryStore) Append(r Record) error {
+	offset, err := s.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(r); err != nil {
+		return err
+	}
+
+	var lenPrefix [4]byte
+	binary.BigEndian.PutUint32(lenPrefix[:], uint32(buf.Len()))
+	if _, err := s.file.Write(lenPrefix[:]); err != nil {
+		return err
+	}
+	if _, err := s.file.Write(buf.Bytes()); err != nil {
+		return err
+	}
+
+	s.index = append(s.index, binaryIndexEntry{TimestampNano: r.Timestamp.UnixNano(), Offset: offset})
+	return nil
+}
+
+// Query seeks to the first record at or after from (via the footer index, if
+// one was loaded) and decodes sequentially until a record after to is seen.
+func (s *BinaryStore) Query(from, to time.Time) ([]Record, error) {
+	startOffset := int64(0)
+	if !from.IsZero() && len(s.index) > 0 {
+		fromNano := from.UnixNano()
+		i := sort.Search(len(s.index), func(i int) bool { return s.index[i].TimestampNano >= fromNano })
+		if i == len(s.index) {
+			return nil, nil
+		}
+		startOffset = s.index[i].Offset
+	}
+
+	if _, err := s.file.Seek(startOffset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	defer s.file.Seek(0, io.SeekEnd) // restore the append position for subsequent Appends
+
+	var out []Record
+	for {
+		var lenPrefix [4]byte
+		if _, err := io.ReadFull(s.file, lenPrefix[:]); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			return nil, err
+		}
+		recordLen := binary.BigEndian.Uint32(lenPrefix[:])
+
+		buf := make([]byte, recordLen)
+		if _, err := io.ReadFull(s.file, buf); err != nil {
+			return nil, fmt.Errorf("store: truncated binary record: %w", err)
+		}
+
+		var r Record
+		if err := gob.NewDecoder(bytes.NewReader(buf)).Decode(&r); err != nil {
+			return nil, err
+		}
+		if !to.IsZero() && r.Timestamp.After(to) {
+			break
+		}
+		if inRange(r.Timestamp, from, to) {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+// Close rewrites the footer index and closes the underlying file.
+func (s *BinaryStore) Close() error {
+	footerOffset, err := s.file.Seek(0, io.SeekEnd)
+	if err != nil {
+		s.file.Close()
+		return err
+	}
+
+	if err := gob.NewEncoder(s.file).Encode(s.index); err != nil {
+		s.file.Close()
+		return err
+	}
+
+	var trailer [binaryTrailerSize]byte
+	copy(trailer[:4], binaryFooterMagic[:])
+	binary.BigEndian.PutUint64(trailer[4:], uint64(footerOffset))
+	if _, err := s.file.Write(trailer[:]); err != nil {
+		s.file.Close()
+		return err
+	}
+
+	return s.file.Close()
+}
@@ -0,0 +1,154 @@
+package store
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// csvVersion is bumped whenever CSVStore's column layout changes; Query uses
+// csvVersionPrefix to decide how many columns to expect, so old exports
+// remain readable.
+const csvVersion = 2
+
+// csvVersionPrefix marks the first line of a v2+ export. A file with no such
+// line is a v1 export: "timestamp,cpu_usage,mem_usage,disk_usage" rows only,
+// with no load-average columns.
+const csvVersionPrefix = "# system-eye-history v"
+
+var csvHeader = []string{"timestamp", "cpu_usage", "mem_usage", "disk_usage", "load1", "load5", "load15"}
+
+// CSVStore is the original flat-CSV HistoryStore, kept for compatibility with
+// existing exports and external tooling that reads this format directly. It
+// has no index: Query re-reads and filters the whole file.
+type CSVStore struct {
+	path   string
+	file   *os.File
+	writer *csv.Writer
+}
+
+// OpenCSVStore opens (creating if necessary) a CSV history file at path,
+// appending to it if it already holds data.
+func OpenCSVStore(path string) (*CSVStore, error) {
+	info, statErr := os.Stat(path)
+	needsHeader := statErr != nil || info.Size() == 0
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &CSVStore{path: path, file: f, writer: csv.NewWriter(f)}
+	if needsHeader {
+		if _, err := fmt.Fprintf(f, "%s%d\n", csvVersionPrefix, csvVersion); err != nil {
+			f.Close()
+			return nil, err
+		}
+		if err := s.writer.Write(csvHeader); err != nil {
+			f.Close()
+			return nil, err
+		}
+		s.writer.Flush()
+		if err := s.writer.Error(); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Append writes r as a new CSV row.
+func (s *CSVStore) Append(r Record) error {
+	record := []string{
+		r.Timestamp.Format(time.RFC3339),
+		fmt.Sprintf("%.2f", r.CPUUsage),
+		fmt.Sprintf("%.2f", r.MemUsage),
+		fmt.Sprintf("%.2f", r.DiskUsage),
+		fmt.Sprintf("%.2f", r.Load1),
+		fmt.Sprintf("%.2f", r.Load5),
+		fmt.Sprintf("%.2f", r.Load15),
+	}
+	if err := s.writer.Write(record); err != nil {
+		return err
+	}
+	s.writer.Flush()
+	return s.writer.Error()
+}
+
+// Query re-reads and filters the whole file, supporting both the legacy v1
+// layout (no version marker, 4 columns) and the current v2+ layout (version
+// marker line followed by a 7-column header).
+func (s *CSVStore) Query(from, to time.Time) ([]Record, error) {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	buffered := bufio.NewReader(f)
+	hasLoadColumns := false
+	if first, err := buffered.Peek(len(csvVersionPrefix)); err == nil && string(first) == csvVersionPrefix {
+		if _, err := buffered.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		hasLoadColumns = true
+	}
+
+	reader := csv.NewReader(buffered)
+	if _, err := reader.Read(); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var out []Record
+	for {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(rec) < 4 {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, rec[0])
+		if err != nil {
+			continue
+		}
+		if !inRange(t, from, to) {
+			continue
+		}
+		cpuVal, err := strconv.ParseFloat(rec[1], 64)
+		if err != nil {
+			continue
+		}
+		memVal, err := strconv.ParseFloat(rec[2], 64)
+		if err != nil {
+			continue
+		}
+		diskVal, err := strconv.ParseFloat(rec[3], 64)
+		if err != nil {
+			continue
+		}
+		r := Record{Timestamp: t, CPUUsage: cpuVal, MemUsage: memVal, DiskUsage: diskVal}
+		if hasLoadColumns && len(rec) >= 7 {
+			r.Load1, _ = strconv.ParseFloat(rec[4], 64)
+			r.Load5, _ = strconv.ParseFloat(rec[5], 64)
+			r.Load15, _ = strconv.ParseFloat(rec[6], 64)
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// Close closes the underlying file.
+func (s *CSVStore) Close() error {
+	return s.file.Close()
+}
@@ -0,0 +1,130 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" database/sql driver
+)
+
+// sqliteSchema holds one row per named metric per sample, rather than one
+// row per Record, so that future metrics can be added without a migration:
+// (ts, metric, value, labels) with an index on (metric, ts) for range scans.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS samples (
+	ts     INTEGER NOT NULL,
+	metric TEXT    NOT NULL,
+	value  REAL    NOT NULL,
+	labels TEXT    NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS samples_metric_ts ON samples (metric, ts);
+`
+
+// sqliteMetrics names the columns of a Record, in the order they're written
+// to and reassembled from the samples table.
+var sqliteMetrics = []string{"cpu_usage", "mem_usage", "disk_usage", "load1", "load5", "load15"}
+
+// SQLiteStore persists Records to a SQLite database via modernc.org/sqlite
+// (a CGO-free, pure-Go driver), enabling indexed time-range queries over
+// months of history without loading the whole file into memory.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// OpenSQLiteStore opens (creating and migrating if necessary) a SQLite
+// history database at path.
+func OpenSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// Append writes r as one row per metric, in a single transaction.
+func (s *SQLiteStore) Append(r Record) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	values := []float64{r.CPUUsage, r.MemUsage, r.DiskUsage, r.Load1, r.Load5, r.Load15}
+	ts := r.Timestamp.UnixNano()
+	for i, metric := range sqliteMetrics {
+		if _, err := tx.Exec(`INSERT INTO samples (ts, metric, value, labels) VALUES (?, ?, ?, '')`, ts, metric, values[i]); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Query returns every Record whose timestamp falls in [from, to], assembled
+// from the per-metric rows the index (metric, ts) lets SQLite seek directly
+// to.
+func (s *SQLiteStore) Query(from, to time.Time) ([]Record, error) {
+	query := `SELECT ts, metric, value FROM samples WHERE metric IN (?, ?, ?, ?, ?, ?)`
+	args := []any{sqliteMetrics[0], sqliteMetrics[1], sqliteMetrics[2], sqliteMetrics[3], sqliteMetrics[4], sqliteMetrics[5]}
+	if !from.IsZero() {
+		query += ` AND ts >= ?`
+		args = append(args, from.UnixNano())
+	}
+	if !to.IsZero() {
+		query += ` AND ts <= ?`
+		args = append(args, to.UnixNano())
+	}
+	query += ` ORDER BY ts`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Rows arrive ordered by ts (per the query above), so appending a new
+	// Record to out the first time each ts is seen keeps out in time order.
+	byTimestamp := map[int64]*Record{}
+	var out []Record
+	for rows.Next() {
+		var ts int64
+		var metric string
+		var value float64
+		if err := rows.Scan(&ts, &metric, &value); err != nil {
+			return nil, err
+		}
+		r, ok := byTimestamp[ts]
+		if !ok {
+			out = append(out, Record{Timestamp: time.Unix(0, ts)})
+			r = &out[len(out)-1]
+			byTimestamp[ts] = r
+		}
+		switch metric {
+		case "cpu_usage":
+			r.CPUUsage = value
+		case "mem_usage":
+			r.MemUsage = value
+		case "disk_usage":
+			r.DiskUsage = value
+		case "load1":
+			r.Load1 = value
+		case "load5":
+			r.Load5 = value
+		case "load15":
+			r.Load15 = value
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// Close closes the underlying database connection.
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
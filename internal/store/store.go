@@ -0,0 +1,104 @@
+// Package store provides a pluggable backend for persisting and querying a
+// history of system-eye's core time-series metrics, replacing the single
+// flat-CSV approach that used to live directly in cmd/dashboard.go.
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"system-eye/internal/collector"
+)
+
+// Record is the subset of a collected Metric persisted to history: the
+// scalar series that get charted over time. Richer per-core, per-device,
+// per-plugin, and cgroup breakdowns are available live via the Collector but
+// are not part of the persisted history.
+type Record struct {
+	Timestamp time.Time
+	CPUUsage  float64
+	MemUsage  float64
+	DiskUsage float64
+	Load1     float64
+	Load5     float64
+	Load15    float64
+}
+
+// RecordFromMetric projects a collector.Metric down to the fields a
+// HistoryStore persists.
+func RecordFromMetric(m collector.Metric) Record {
+	return Record{
+		Timestamp: m.Timestamp,
+		CPUUsage:  m.CPUUsage,
+		MemUsage:  m.MemUsage,
+		DiskUsage: m.DiskUsage,
+		Load1:     m.LoadAvg.Load1,
+		Load5:     m.LoadAvg.Load5,
+		Load15:    m.LoadAvg.Load15,
+	}
+}
+
+// HistoryStore persists and queries a time series of Records. Implementations
+// need not be safe for concurrent use; callers serialize access themselves
+// (the dashboard, for instance, only ever touches a store from its own
+// goroutine).
+type HistoryStore interface {
+	// Append persists r.
+	Append(r Record) error
+	// Query returns every Record with Timestamp in [from, to]. A zero from
+	// or to leaves that bound open.
+	Query(from, to time.Time) ([]Record, error)
+	// Close flushes and releases any resources held by the store.
+	Close() error
+}
+
+// Backend names a HistoryStore implementation, as inferred from a file's
+// extension by BackendForPath.
+type Backend string
+
+const (
+	BackendCSV    Backend = "csv"
+	BackendBinary Backend = "binary"
+	BackendSQLite Backend = "sqlite"
+)
+
+// BackendForPath infers the backend from filename's extension, defaulting to
+// BackendCSV for an unrecognized or missing extension.
+func BackendForPath(filename string) Backend {
+	switch {
+	case strings.HasSuffix(filename, ".db"), strings.HasSuffix(filename, ".sqlite"), strings.HasSuffix(filename, ".sqlite3"):
+		return BackendSQLite
+	case strings.HasSuffix(filename, ".bin"), strings.HasSuffix(filename, ".log"):
+		return BackendBinary
+	default:
+		return BackendCSV
+	}
+}
+
+// Open opens (creating if necessary) the HistoryStore of the given backend at
+// path.
+func Open(backend Backend, path string) (HistoryStore, error) {
+	switch backend {
+	case BackendCSV, "":
+		return OpenCSVStore(path)
+	case BackendBinary:
+		return OpenBinaryStore(path)
+	case BackendSQLite:
+		return OpenSQLiteStore(path)
+	default:
+		return nil, fmt.Errorf("store: unknown backend %q", backend)
+	}
+}
+
+// inRange reports whether ts falls within [from, to], treating a zero from or
+// to as an open bound.
+func inRange(ts, from, to time.Time) bool {
+	if !from.IsZero() && ts.Before(from) {
+		return false
+	}
+	if !to.IsZero() && ts.After(to) {
+		return false
+	}
+	return true
+}
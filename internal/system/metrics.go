@@ -5,6 +5,8 @@ import (
 
 	"github.com/shirou/gopsutil/cpu"
 	"github.com/shirou/gopsutil/disk"
+	"github.com/shirou/gopsutil/host"
+	"github.com/shirou/gopsutil/load"
 	"github.com/shirou/gopsutil/mem"
 )
 
@@ -37,3 +39,94 @@ func GetDiskUsage(path string) (float64, error) {
 	}
 	return usageStat.UsedPercent, nil
 }
+
+// GetPerCPUUsage returns the usage percentage over a 1-second interval for
+// each logical CPU core, indexed by core number.
+func GetPerCPUUsage() ([]float64, error) {
+	return cpu.Percent(time.Second, true)
+}
+
+// GetDiskUsageAll returns the usage percentage for every mounted partition,
+// keyed by mountpoint.
+func GetDiskUsageAll() (map[string]float64, error) {
+	partitions, err := disk.Partitions(false)
+	if err != nil {
+		return nil, err
+	}
+	usage := make(map[string]float64, len(partitions))
+	for _, p := range partitions {
+		stat, err := disk.Usage(p.Mountpoint)
+		if err != nil {
+			continue
+		}
+		usage[p.Mountpoint] = stat.UsedPercent
+	}
+	return usage, nil
+}
+
+// CPUTimes breaks down one CPU core's time accounting, in seconds.
+type CPUTimes struct {
+	Core    string
+	User    float64
+	System  float64
+	Iowait  float64
+	Irq     float64
+	Softirq float64
+}
+
+// GetCPUTimes returns the user/sys/iowait/irq/softirq breakdown for every
+// logical CPU core.
+func GetCPUTimes() ([]CPUTimes, error) {
+	times, err := cpu.Times(true)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]CPUTimes, len(times))
+	for i, t := range times {
+		out[i] = CPUTimes{
+			Core:    t.CPU,
+			User:    t.User,
+			System:  t.System,
+			Iowait:  t.Iowait,
+			Irq:     t.Irq,
+			Softirq: t.Softirq,
+		}
+	}
+	return out, nil
+}
+
+// LoadAverage holds the standard 1/5/15 minute load averages.
+type LoadAverage struct {
+	Load1  float64
+	Load5  float64
+	Load15 float64
+}
+
+// GetLoadAverage returns the system's 1/5/15 minute load averages.
+func GetLoadAverage() (LoadAverage, error) {
+	avg, err := load.Avg()
+	if err != nil {
+		return LoadAverage{}, err
+	}
+	return LoadAverage{Load1: avg.Load1, Load5: avg.Load5, Load15: avg.Load15}, nil
+}
+
+// Temperature is a single sensor reading, in degrees Celsius.
+type Temperature struct {
+	Sensor  string
+	Celsius float64
+}
+
+// GetTemperatures returns the readings from every temperature sensor the
+// host exposes.
+func GetTemperatures() ([]Temperature, error) {
+	stats, err := host.SensorsTemperatures()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Temperature, len(stats))
+	for i, s := range stats {
+		out[i] = Temperature{Sensor: s.SensorKey, Celsius: s.Temperature}
+	}
+	return out, nil
+}
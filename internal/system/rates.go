@@ -0,0 +1,115 @@
+package system
+
+import (
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/disk"
+	"github.com/shirou/gopsutil/net"
+)
+
+// NetIOStat reports one network interface's throughput, computed as the
+// delta since the previous call to GetNetIOCounters.
+type NetIOStat struct {
+	Interface       string
+	BytesSentRate   float64 // bytes/sec
+	BytesRecvRate   float64 // bytes/sec
+	PacketsSentRate float64 // packets/sec
+	PacketsRecvRate float64 // packets/sec
+}
+
+var (
+	netIOMu   sync.Mutex
+	lastNetIO = map[string]net.IOCountersStat{}
+	lastNetAt time.Time
+)
+
+// GetNetIOCounters returns per-interface network throughput. The first call
+// after process start (or after an interface first appears) reports a zero
+// rate, since there is no prior sample to diff against.
+func GetNetIOCounters() ([]NetIOStat, error) {
+	counters, err := net.IOCounters(true)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	netIOMu.Lock()
+	defer netIOMu.Unlock()
+
+	elapsed := now.Sub(lastNetAt).Seconds()
+	out := make([]NetIOStat, len(counters))
+	for i, c := range counters {
+		prev, ok := lastNetIO[c.Name]
+		var stat NetIOStat
+		stat.Interface = c.Name
+		if ok && elapsed > 0 {
+			stat.BytesSentRate = rate(c.BytesSent, prev.BytesSent, elapsed)
+			stat.BytesRecvRate = rate(c.BytesRecv, prev.BytesRecv, elapsed)
+			stat.PacketsSentRate = rate(c.PacketsSent, prev.PacketsSent, elapsed)
+			stat.PacketsRecvRate = rate(c.PacketsRecv, prev.PacketsRecv, elapsed)
+		}
+		out[i] = stat
+		lastNetIO[c.Name] = c
+	}
+	lastNetAt = now
+
+	return out, nil
+}
+
+// DiskIOStat reports one block device's throughput and IOPS, computed as the
+// delta since the previous call to GetDiskIOCounters.
+type DiskIOStat struct {
+	Device         string
+	ReadBytesRate  float64 // bytes/sec
+	WriteBytesRate float64 // bytes/sec
+	ReadIOPS       float64 // reads/sec
+	WriteIOPS      float64 // writes/sec
+}
+
+var (
+	diskIOMu   sync.Mutex
+	lastDiskIO = map[string]disk.IOCountersStat{}
+	lastDiskAt time.Time
+)
+
+// GetDiskIOCounters returns per-device disk throughput and IOPS. The first
+// call after process start (or after a device first appears) reports a zero
+// rate, since there is no prior sample to diff against.
+func GetDiskIOCounters() ([]DiskIOStat, error) {
+	counters, err := disk.IOCounters()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	diskIOMu.Lock()
+	defer diskIOMu.Unlock()
+
+	elapsed := now.Sub(lastDiskAt).Seconds()
+	out := make([]DiskIOStat, 0, len(counters))
+	for name, c := range counters {
+		prev, ok := lastDiskIO[name]
+		stat := DiskIOStat{Device: name}
+		if ok && elapsed > 0 {
+			stat.ReadBytesRate = rate(c.ReadBytes, prev.ReadBytes, elapsed)
+			stat.WriteBytesRate = rate(c.WriteBytes, prev.WriteBytes, elapsed)
+			stat.ReadIOPS = rate(c.ReadCount, prev.ReadCount, elapsed)
+			stat.WriteIOPS = rate(c.WriteCount, prev.WriteCount, elapsed)
+		}
+		out = append(out, stat)
+		lastDiskIO[name] = c
+	}
+	lastDiskAt = now
+
+	return out, nil
+}
+
+// rate computes (current-previous)/elapsed, clamping to 0 if the counter
+// appears to have reset (e.g. the device was replaced).
+func rate(current, previous uint64, elapsedSeconds float64) float64 {
+	if current < previous {
+		return 0
+	}
+	return float64(current-previous) / elapsedSeconds
+}